@@ -0,0 +1,7 @@
+-- +migrate Up
+CREATE UNIQUE INDEX idx_user_sessions_token ON user_sessions ("token");
+CREATE INDEX idx_user_sessions_user_id ON user_sessions ("user_id");
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_user_sessions_user_id;
+DROP INDEX IF EXISTS idx_user_sessions_token;
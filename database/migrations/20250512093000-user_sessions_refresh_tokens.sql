@@ -0,0 +1,19 @@
+-- +migrate Up
+ALTER TABLE user_sessions
+    ADD COLUMN "family_id" VARCHAR(64),
+    ADD COLUMN "refresh_token_hash" VARCHAR(64),
+    ADD COLUMN "refresh_expires_at" TIMESTAMP,
+    ADD COLUMN "revoked_at" TIMESTAMP DEFAULT NULL;
+
+CREATE UNIQUE INDEX idx_user_sessions_refresh_token_hash ON user_sessions ("refresh_token_hash");
+CREATE INDEX idx_user_sessions_family_id ON user_sessions ("family_id");
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_user_sessions_family_id;
+DROP INDEX IF EXISTS idx_user_sessions_refresh_token_hash;
+
+ALTER TABLE user_sessions
+    DROP COLUMN "revoked_at",
+    DROP COLUMN "refresh_expires_at",
+    DROP COLUMN "refresh_token_hash",
+    DROP COLUMN "family_id";
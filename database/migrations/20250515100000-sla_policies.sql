@@ -0,0 +1,36 @@
+-- +migrate Up
+CREATE TABLE sla_policies (
+    "id" SERIAL PRIMARY KEY,
+    "name" VARCHAR(255) NOT NULL,
+    "customer_id" INT REFERENCES users("id") ON DELETE CASCADE,
+    "category" VARCHAR(100) NOT NULL DEFAULT '',
+    "queue" VARCHAR(100) NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE sla_targets (
+    "id" SERIAL PRIMARY KEY,
+    "sla_policy_id" INT NOT NULL REFERENCES sla_policies("id") ON DELETE CASCADE,
+    "priority" priority NOT NULL,
+    "first_response_minutes" INT NOT NULL,
+    "resolution_minutes" INT NOT NULL,
+    UNIQUE ("sla_policy_id", "priority")
+);
+
+ALTER TABLE tickets
+    ADD COLUMN "category" VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN "queue" VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN "sla_policy_id" INT REFERENCES sla_policies("id") ON DELETE SET NULL,
+    ADD COLUMN "first_response_due_by" TIMESTAMP;
+
+-- +migrate Down
+ALTER TABLE tickets
+    DROP COLUMN "first_response_due_by",
+    DROP COLUMN "sla_policy_id",
+    DROP COLUMN "queue",
+    DROP COLUMN "category";
+
+DROP TABLE IF EXISTS sla_targets;
+DROP TABLE IF EXISTS sla_policies;
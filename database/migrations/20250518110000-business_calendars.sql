@@ -0,0 +1,36 @@
+-- +migrate Up
+CREATE TABLE business_calendars (
+    "id" SERIAL PRIMARY KEY,
+    "name" VARCHAR(255) NOT NULL,
+    "timezone" VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    "is_default" BOOLEAN NOT NULL DEFAULT FALSE,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE business_hours (
+    "id" SERIAL PRIMARY KEY,
+    "business_calendar_id" INT NOT NULL REFERENCES business_calendars("id") ON DELETE CASCADE,
+    "weekday" SMALLINT NOT NULL CHECK ("weekday" BETWEEN 0 AND 6),
+    "start_time" VARCHAR(5) NOT NULL,
+    "end_time" VARCHAR(5) NOT NULL
+);
+
+CREATE TABLE business_holidays (
+    "id" SERIAL PRIMARY KEY,
+    "business_calendar_id" INT NOT NULL REFERENCES business_calendars("id") ON DELETE CASCADE,
+    "date" DATE NOT NULL,
+    "name" VARCHAR(255) NOT NULL DEFAULT '',
+    UNIQUE ("business_calendar_id", "date")
+);
+
+ALTER TABLE sla_policies
+    ADD COLUMN "business_calendar_id" INT REFERENCES business_calendars("id") ON DELETE SET NULL;
+
+-- +migrate Down
+ALTER TABLE sla_policies DROP COLUMN "business_calendar_id";
+
+DROP TABLE IF EXISTS business_holidays;
+DROP TABLE IF EXISTS business_hours;
+DROP TABLE IF EXISTS business_calendars;
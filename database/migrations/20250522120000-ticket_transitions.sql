@@ -0,0 +1,26 @@
+-- +migrate Up
+CREATE TABLE ticket_transitions (
+    "id" SERIAL PRIMARY KEY,
+    "from_status" status NOT NULL,
+    "to_status" status NOT NULL,
+    "roles" TEXT[] NOT NULL DEFAULT '{}',
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE ("from_status", "to_status")
+);
+
+INSERT INTO ticket_transitions ("from_status", "to_status", "roles") VALUES
+    ('open', 'in_progress', '{}'),
+    ('in_progress', 'pending', '{}'),
+    ('in_progress', 'resolved', '{admin,support}'),
+    ('pending', 'in_progress', '{}'),
+    ('pending', 'resolved', '{admin,support}'),
+    ('resolved', 'closed', '{}'),
+    ('resolved', 'open', '{}');
+
+ALTER TABLE ticket_histories ADD COLUMN "from_status" VARCHAR(20) NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE ticket_histories DROP COLUMN "from_status";
+
+DROP TABLE IF EXISTS ticket_transitions;
@@ -0,0 +1,19 @@
+-- +migrate Up
+CREATE TABLE outbox_messages (
+    "id" BIGSERIAL PRIMARY KEY,
+    "exchange" VARCHAR(100) NOT NULL,
+    "routing_key" VARCHAR(100) NOT NULL,
+    "content_type" VARCHAR(100) NOT NULL DEFAULT 'application/json',
+    "payload" BYTEA NOT NULL,
+    "status" VARCHAR(20) NOT NULL DEFAULT 'pending',
+    "attempts" INT NOT NULL DEFAULT 0,
+    "last_error" TEXT NOT NULL DEFAULT '',
+    "available_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "dispatched_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX outbox_messages_pending_idx ON outbox_messages ("available_at") WHERE "status" = 'pending';
+
+-- +migrate Down
+DROP TABLE IF EXISTS outbox_messages;
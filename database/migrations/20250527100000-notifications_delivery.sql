@@ -0,0 +1,17 @@
+-- +migrate Up
+ALTER TABLE notifications
+    ADD COLUMN "attempts" INT NOT NULL DEFAULT 0,
+    ADD COLUMN "last_error" TEXT NOT NULL DEFAULT '',
+    ADD COLUMN "sent_at" TIMESTAMP,
+    ADD COLUMN "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_notifications_status ON notifications ("status");
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_notifications_status;
+
+ALTER TABLE notifications
+    DROP COLUMN "updated_at",
+    DROP COLUMN "sent_at",
+    DROP COLUMN "last_error",
+    DROP COLUMN "attempts";
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE notifications
+    ADD COLUMN "event" VARCHAR(50) NOT NULL DEFAULT 'generic',
+    ADD COLUMN "data" JSONB NOT NULL DEFAULT '{}';
+
+-- +migrate Down
+ALTER TABLE notifications
+    DROP COLUMN "data",
+    DROP COLUMN "event";
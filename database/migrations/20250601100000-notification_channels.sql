@@ -0,0 +1,21 @@
+-- +migrate Up
+CREATE TABLE notification_channel_settings (
+    "id" SERIAL PRIMARY KEY,
+    "user_id" INT NOT NULL REFERENCES users("id") ON DELETE CASCADE,
+    "channel" VARCHAR(20) NOT NULL,
+    "target" TEXT NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE ("user_id", "channel", "target")
+);
+
+ALTER TABLE notifications
+    ADD COLUMN "channel" VARCHAR(20) NOT NULL DEFAULT 'email',
+    ADD COLUMN "target" TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE notifications
+    DROP COLUMN "target",
+    DROP COLUMN "channel";
+
+DROP TABLE IF EXISTS notification_channel_settings;
@@ -0,0 +1,40 @@
+-- +migrate Up
+CREATE TABLE notification_settings (
+    "user_id" INT PRIMARY KEY REFERENCES users("id") ON DELETE CASCADE,
+    "timezone" VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    "quiet_hours_start" VARCHAR(5) NOT NULL DEFAULT '',
+    "quiet_hours_end" VARCHAR(5) NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE notification_preferences (
+    "id" SERIAL PRIMARY KEY,
+    "user_id" INT NOT NULL REFERENCES users("id") ON DELETE CASCADE,
+    "event" VARCHAR(50) NOT NULL,
+    "channel" VARCHAR(20) NOT NULL,
+    "enabled" BOOLEAN NOT NULL DEFAULT TRUE,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE ("user_id", "event", "channel")
+);
+
+-- held notifications wait for the end of quiet hours and are sent as a
+-- digest, which belongs to no single ticket
+ALTER TABLE notifications
+    ADD COLUMN "held_until" TIMESTAMP,
+    ALTER COLUMN "ticket_id" DROP NOT NULL;
+
+CREATE INDEX idx_notifications_held_until ON notifications ("held_until") WHERE "status" = 'held';
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_notifications_held_until;
+
+DELETE FROM notifications WHERE "ticket_id" IS NULL;
+
+ALTER TABLE notifications
+    ALTER COLUMN "ticket_id" SET NOT NULL,
+    DROP COLUMN "held_until";
+
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notification_settings;
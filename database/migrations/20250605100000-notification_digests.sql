@@ -0,0 +1,21 @@
+-- +migrate Up
+ALTER TABLE notification_settings
+    ADD COLUMN "digest_mode" VARCHAR(10) NOT NULL DEFAULT 'off',
+    ADD COLUMN "digest_hour" INT NOT NULL DEFAULT 8;
+
+ALTER TABLE notifications
+    ADD COLUMN "digest_id" INT REFERENCES notifications("id") ON DELETE SET NULL,
+    ADD COLUMN "digested_at" TIMESTAMP;
+
+CREATE INDEX idx_notifications_digest_id ON notifications ("digest_id");
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_notifications_digest_id;
+
+ALTER TABLE notifications
+    DROP COLUMN "digested_at",
+    DROP COLUMN "digest_id";
+
+ALTER TABLE notification_settings
+    DROP COLUMN "digest_hour",
+    DROP COLUMN "digest_mode";
@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE inbound_messages (
+    "id" SERIAL PRIMARY KEY,
+    "message_id" VARCHAR(998) NOT NULL UNIQUE,
+    "ticket_id" INT NOT NULL REFERENCES tickets("id") ON DELETE CASCADE,
+    "comment_id" INT REFERENCES comments("id") ON DELETE SET NULL,
+    "user_id" INT NOT NULL REFERENCES users("id") ON DELETE CASCADE,
+    "subject" VARCHAR(255) NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_inbound_messages_ticket_id ON inbound_messages ("ticket_id");
+
+-- +migrate Down
+DROP TABLE IF EXISTS inbound_messages;
@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE notifications
+    ADD COLUMN "read_at" TIMESTAMP;
+
+CREATE INDEX idx_notifications_inbox ON notifications ("user_id", "id") WHERE "channel" = 'in_app';
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_notifications_inbox;
+
+DELETE FROM notifications WHERE "channel" = 'in_app';
+
+ALTER TABLE notifications
+    DROP COLUMN "read_at";
@@ -0,0 +1,32 @@
+-- +migrate Up
+CREATE TABLE webhook_subscriptions (
+    "id" SERIAL PRIMARY KEY,
+    "url" VARCHAR(2048) NOT NULL,
+    "events" TEXT[] NOT NULL DEFAULT '{}',
+    "secret" VARCHAR(255) NOT NULL,
+    "active" BOOLEAN NOT NULL DEFAULT TRUE,
+    "created_by" INT REFERENCES users("id") ON DELETE SET NULL,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE webhook_deliveries (
+    "id" SERIAL PRIMARY KEY,
+    "subscription_id" INT NOT NULL REFERENCES webhook_subscriptions("id") ON DELETE CASCADE,
+    "event_id" VARCHAR(64) NOT NULL,
+    "event_type" VARCHAR(50) NOT NULL,
+    "payload" BYTEA NOT NULL,
+    "status" VARCHAR(20) NOT NULL DEFAULT 'pending',
+    "attempts" INT NOT NULL DEFAULT 0,
+    "response_status" INT NOT NULL DEFAULT 0,
+    "last_error" TEXT NOT NULL DEFAULT '',
+    "delivered_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_deliveries_subscription_id ON webhook_deliveries ("subscription_id", "id");
+
+-- +migrate Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
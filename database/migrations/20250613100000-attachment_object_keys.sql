@@ -0,0 +1,25 @@
+-- +migrate Up
+ALTER TABLE attachments RENAME COLUMN "file_path" TO "object_key";
+ALTER TABLE attachments ADD COLUMN "filename" VARCHAR(255) NOT NULL DEFAULT '';
+
+-- inbound mail attachments used to be saved as
+-- ./storage/attachments/<ticket>/<16 hex>-<name>, which has the same layout
+-- as the new keys below tickets/. The files have to be moved there, see the
+-- README.
+UPDATE attachments SET
+    "filename" = regexp_replace("object_key", '^.*/[0-9a-f]{16}-', ''),
+    "object_key" = regexp_replace("object_key", '^(\./)?storage/attachments/', 'tickets/')
+WHERE "object_key" ~ '^(\./)?storage/attachments/';
+
+-- uploads used to be saved as ./uploads/tickets/<unix nano>_<name>, which is
+-- the same file under the default local storage directory. Files saved
+-- anywhere else keep their old path as key and must be moved to match.
+UPDATE attachments SET
+    "filename" = regexp_replace("object_key", '^.*/([0-9]+_)?', ''),
+    "object_key" = regexp_replace("object_key", '^(\./)?uploads/', '')
+WHERE "filename" = '';
+
+-- +migrate Down
+UPDATE attachments SET "object_key" = 'uploads/' || "object_key" WHERE "object_key" LIKE 'tickets/%';
+ALTER TABLE attachments DROP COLUMN "filename";
+ALTER TABLE attachments RENAME COLUMN "object_key" TO "file_path";
@@ -0,0 +1,10 @@
+-- +migrate Up
+-- attachments uploaded before this migration have no checksum and a size of 0
+ALTER TABLE attachments ADD COLUMN "mime_type" VARCHAR(255) NOT NULL DEFAULT 'application/octet-stream';
+ALTER TABLE attachments ADD COLUMN "size" BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE attachments ADD COLUMN "sha256" VARCHAR(64) NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE attachments DROP COLUMN "sha256";
+ALTER TABLE attachments DROP COLUMN "size";
+ALTER TABLE attachments DROP COLUMN "mime_type";
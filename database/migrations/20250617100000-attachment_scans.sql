@@ -0,0 +1,14 @@
+-- +migrate Up
+-- attachments uploaded before scanning existed are left downloadable; new
+-- ones start quarantined
+ALTER TABLE attachments ADD COLUMN "scan_status" VARCHAR(20) NOT NULL DEFAULT 'clean';
+ALTER TABLE attachments ALTER COLUMN "scan_status" SET DEFAULT 'quarantined';
+ALTER TABLE attachments ADD COLUMN "scan_signature" VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE attachments ADD COLUMN "scanned_at" TIMESTAMP;
+ALTER TABLE attachments ADD COLUMN "uploaded_by" INT REFERENCES users("id") ON DELETE SET NULL;
+
+-- +migrate Down
+ALTER TABLE attachments DROP COLUMN "uploaded_by";
+ALTER TABLE attachments DROP COLUMN "scanned_at";
+ALTER TABLE attachments DROP COLUMN "scan_signature";
+ALTER TABLE attachments DROP COLUMN "scan_status";
@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE tickets ADD COLUMN "first_responded_at" TIMESTAMP;
+-- due dates the SLA breach scan last warned about, so a due date moved by a
+-- pause is warned about again
+ALTER TABLE tickets ADD COLUMN "first_response_warned_for" TIMESTAMP;
+ALTER TABLE tickets ADD COLUMN "due_by_warned_for" TIMESTAMP;
+
+-- +migrate Down
+ALTER TABLE tickets DROP COLUMN "due_by_warned_for";
+ALTER TABLE tickets DROP COLUMN "first_response_warned_for";
+ALTER TABLE tickets DROP COLUMN "first_responded_at";
@@ -38,6 +38,10 @@ func JWTExp() time.Duration {
 	return viper.GetDuration("jwt.exp")
 }
 
+func JWTRefreshExp() time.Duration {
+	return viper.GetDuration("jwt.refresh_exp")
+}
+
 func GetRedisHost() string {
 	return viper.GetString("redis.host")
 }
@@ -45,3 +49,148 @@ func GetRedisHost() string {
 func GetRedisDB() int {
 	return viper.GetInt("redis.db")
 }
+
+func OutboxBatchSize() int {
+	return viper.GetInt("outbox.batch_size")
+}
+
+func OutboxMaxAttempts() int {
+	return viper.GetInt("outbox.max_attempts")
+}
+
+func OutboxPollInterval() time.Duration {
+	return viper.GetDuration("outbox.poll_interval")
+}
+
+// OutboxRelayInServer reports whether httpsrv runs the outbox relay itself.
+// Turn it off when the relay runs as its own process.
+func OutboxRelayInServer() bool {
+	return viper.GetBool("outbox.relay_in_server")
+}
+
+// EventsPublishRabbitMQ reports whether domain events are also published to
+// the domain event exchange.
+func EventsPublishRabbitMQ() bool {
+	return viper.GetBool("events.publish_rabbitmq")
+}
+
+func AppBaseURL() string {
+	return viper.GetString("app.base_url")
+}
+
+func EmailTemplateDir() string {
+	return viper.GetString("email.template_dir")
+}
+
+func NotificationDigestInterval() time.Duration {
+	return viper.GetDuration("notification.digest_interval")
+}
+
+// SLAWarningBefore is how long before a first response or resolution falls
+// due that the assignee is warned.
+func SLAWarningBefore() time.Duration {
+	return viper.GetDuration("sla.warning_before")
+}
+
+func SLAScanInterval() time.Duration {
+	return viper.GetDuration("sla.scan_interval")
+}
+
+func InboundMaildir() string {
+	return viper.GetString("inbound.maildir")
+}
+
+func InboundPollInterval() time.Duration {
+	return viper.GetDuration("inbound.poll_interval")
+}
+
+func InboundSMTPAddr() string {
+	return viper.GetString("inbound.smtp_addr")
+}
+
+func InboundSMTPDomain() string {
+	return viper.GetString("inbound.smtp_domain")
+}
+
+func InboundMaxMessageSize() int64 {
+	return viper.GetInt64("inbound.max_message_size")
+}
+
+// InboundAuthservID is the authserv-id of the Authentication-Results header
+// the receiving MTA adds. Senders are only trusted with their staff role
+// when that header records a DMARC pass; empty trusts nobody.
+func InboundAuthservID() string {
+	return viper.GetString("inbound.authserv_id")
+}
+
+func InboundDefaultAssignee() int64 {
+	return viper.GetInt64("inbound.default_assignee")
+}
+
+func InboundDefaultPriority() string {
+	return viper.GetString("inbound.default_priority")
+}
+
+func InboundQueue() string {
+	return viper.GetString("inbound.queue")
+}
+
+func StorageDriver() string {
+	return viper.GetString("storage.driver")
+}
+
+func StorageLocalDir() string {
+	return viper.GetString("storage.local_dir")
+}
+
+func StorageSignedURLTTL() time.Duration {
+	return viper.GetDuration("storage.signed_url_ttl")
+}
+
+func StorageMaxUploadSize() int64 {
+	return viper.GetInt64("storage.max_upload_size")
+}
+
+func StorageAllowedTypes() []string {
+	return viper.GetStringSlice("storage.allowed_types")
+}
+
+func StorageDeniedTypes() []string {
+	return viper.GetStringSlice("storage.denied_types")
+}
+
+func StorageS3Endpoint() string {
+	return viper.GetString("storage.s3.endpoint")
+}
+
+func StorageS3Region() string {
+	return viper.GetString("storage.s3.region")
+}
+
+func StorageS3Bucket() string {
+	return viper.GetString("storage.s3.bucket")
+}
+
+func StorageS3AccessKey() string {
+	return viper.GetString("storage.s3.access_key")
+}
+
+func StorageS3SecretKey() string {
+	return viper.GetString("storage.s3.secret_key")
+}
+
+func StorageS3PathStyle() bool {
+	return viper.GetBool("storage.s3.path_style")
+}
+
+func ScannerDriver() string {
+	return viper.GetString("scanner.driver")
+}
+
+func ScannerClamdAddress() string {
+	return viper.GetString("scanner.clamd.address")
+}
+
+func ScannerClamdTimeout() time.Duration {
+	return viper.GetDuration("scanner.clamd.timeout")
+}
@@ -11,6 +11,37 @@ func LoadWithViper() {
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(".")
 
+	viper.SetDefault("jwt.exp", "15m")
+	viper.SetDefault("jwt.refresh_exp", "720h")
+	viper.SetDefault("app.base_url", "http://localhost:3000")
+	viper.SetDefault("email.template_dir", "./templates/email")
+	viper.SetDefault("notification.digest_interval", "1m")
+	viper.SetDefault("sla.warning_before", "30m")
+	viper.SetDefault("sla.scan_interval", "1m")
+	viper.SetDefault("inbound.poll_interval", "10s")
+	viper.SetDefault("inbound.smtp_domain", "localhost")
+	viper.SetDefault("inbound.max_message_size", 25<<20)
+	viper.SetDefault("inbound.default_priority", "medium")
+	viper.SetDefault("inbound.queue", "email")
+	viper.SetDefault("storage.driver", "local")
+	viper.SetDefault("storage.local_dir", "./uploads")
+	viper.SetDefault("storage.signed_url_ttl", "5m")
+	viper.SetDefault("storage.max_upload_size", 25<<20)
+	viper.SetDefault("storage.denied_types", []string{
+		"application/x-msdownload", "application/x-executable", "application/x-mach-binary", "text/x-shellscript",
+		".exe", ".dll", ".bat", ".cmd", ".com", ".msi", ".scr", ".ps1", ".vbs", ".js", ".jar", ".sh",
+	})
+	viper.SetDefault("storage.s3.region", "us-east-1")
+	viper.SetDefault("storage.s3.path_style", true)
+	viper.SetDefault("scanner.driver", "clamd")
+	viper.SetDefault("scanner.clamd.address", "tcp://localhost:3310")
+	viper.SetDefault("scanner.clamd.timeout", "60s")
+	viper.SetDefault("events.publish_rabbitmq", false)
+	viper.SetDefault("outbox.batch_size", 100)
+	viper.SetDefault("outbox.max_attempts", 10)
+	viper.SetDefault("outbox.poll_interval", "1s")
+	viper.SetDefault("outbox.relay_in_server", true)
+
 	if err := viper.ReadInConfig(); err != nil {
 		log.Fatalf("Error reading config file, %s", err)
 	}
@@ -0,0 +1,91 @@
+package console
+
+import (
+	"context"
+	"helpdesk-ticketing-system/database"
+	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/repository"
+	"helpdesk-ticketing-system/internal/usecase"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var digestWatch bool
+
+func init() {
+	rootCmd.AddCommand(digestCMD)
+
+	digestCMD.Flags().BoolVar(&digestWatch, "watch", false, "Keep sending digests every notification.digest_interval")
+}
+
+var digestCMD = &cobra.Command{
+	Use:   "digest",
+	Short: "Send the notification digests that are due",
+	Long: "Send one digest per recipient for the held notifications that are due. Run it from cron or with --watch, " +
+		"and set notification.digest_interval to 0 so the HTTP server does not send them as well.",
+	Run: sendDigests,
+}
+
+func sendDigests(cmd *cobra.Command, args []string) {
+	config.LoadWithViper()
+	config.LoadWithGetenv()
+
+	postgresDB := database.NewPostgres()
+	sqlDB, err := postgresDB.DB()
+	if err != nil {
+		log.Fatalf("Failed to get SQL DB from Gorm: %v", err)
+	}
+	defer sqlDB.Close()
+
+	redis := database.NewRedis()
+	defer redis.Close()
+
+	// digests go out through the outbox, so neither the broker nor the
+	// templates are needed here, and no ticket events are handled
+	unitOfWork := repository.NewUnitOfWork(postgresDB)
+	outboxUsecase := usecase.NewOutboxUsecase(repository.NewOutboxRepo(postgresDB), unitOfWork, nil)
+	notificationUsecase := usecase.NewNotificationUsecase(
+		repository.NewNotificationRepo(postgresDB),
+		repository.NewNotificationChannelRepo(postgresDB),
+		repository.NewNotificationPreferenceRepo(postgresDB),
+		nil,
+		repository.NewTicketRepo(postgresDB, redis),
+		outboxUsecase,
+		unitOfWork,
+		nil,
+		nil,
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	interval := config.NotificationDigestInterval()
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	for {
+		sent, err := notificationUsecase.SendDigests(ctx)
+		if err != nil {
+			logrus.Error("Failed to send notification digests: ", err)
+		} else {
+			logrus.Infof("Sent %d notification digests", sent)
+		}
+
+		if !digestWatch {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
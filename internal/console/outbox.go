@@ -0,0 +1,62 @@
+package console
+
+import (
+	"context"
+	"helpdesk-ticketing-system/database"
+	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/model"
+	"helpdesk-ticketing-system/internal/repository"
+	"helpdesk-ticketing-system/internal/usecase"
+	"helpdesk-ticketing-system/internal/worker"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/spf13/cobra"
+)
+
+var relayOnce bool
+
+func init() {
+	rootCmd.AddCommand(outboxCMD)
+	outboxCMD.AddCommand(outboxRelayCMD)
+
+	outboxRelayCMD.Flags().BoolVar(&relayOnce, "once", false, "Publish a single batch and exit")
+}
+
+var outboxCMD = &cobra.Command{
+	Use:   "outbox",
+	Short: "Manage the transactional outbox",
+}
+
+var outboxRelayCMD = &cobra.Command{
+	Use:   "relay",
+	Short: "Publish pending outbox messages to RabbitMQ",
+	Long: "Poll the outbox table and publish pending messages to RabbitMQ, retrying failures with backoff. " +
+		"httpsrv runs the same relay unless outbox.relay_in_server is false.",
+	Run: outboxRelay,
+}
+
+func outboxRelay(cmd *cobra.Command, args []string) {
+	config.LoadWithViper()
+	config.LoadWithGetenv()
+
+	postgresDB := database.NewPostgres()
+	sqlDB, err := postgresDB.DB()
+	if err != nil {
+		log.Fatalf("Failed to get SQL DB from Gorm: %v", err)
+	}
+	defer sqlDB.Close()
+
+	unitOfWork := repository.NewUnitOfWork(postgresDB)
+	outboxRepo := repository.NewOutboxRepo(postgresDB)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	worker.RunOutboxRelay(ctx, func(ch *amqp.Channel) model.IOutboxUsecase {
+		return usecase.NewOutboxUsecase(outboxRepo, unitOfWork, ch)
+	}, relayOnce)
+}
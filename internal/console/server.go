@@ -1,7 +1,10 @@
 package console
 
 import (
+	"context"
 	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
 	"helpdesk-ticketing-system/internal/repository"
 	"helpdesk-ticketing-system/internal/usecase"
 	"log"
@@ -11,6 +14,7 @@ import (
 	"helpdesk-ticketing-system/database"
 
 	"github.com/labstack/echo/v4"
+	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
@@ -46,42 +50,111 @@ func httpServer(cmd *cobra.Command, args []string) {
 
 	esClient := config.NewClient()
 
-	rmqChannel, err := config.InitRabbitMQ()
+	rmqConn, err := config.DialRabbitMQ()
+	if err != nil {
+		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
+	}
+	defer rmqConn.Close()
+
+	rmqChannel, err := config.OpenRabbitMQChannel(rmqConn)
 	if err != nil {
 		log.Fatalf("Failed to initialize RabbitMQ channel: %v", err)
 	}
 	defer rmqChannel.Close()
 
-	worker.StartEmailWorker(rmqChannel)
+	emailTemplates, err := helper.LoadEmailTemplates(config.EmailTemplateDir())
+	if err != nil {
+		log.Fatalf("Failed to load email templates: %v", err)
+	}
 
-	userRepo := repository.NewUserRepo(postgresDB)
+	unitOfWork := repository.NewUnitOfWork(postgresDB)
+	userRepo := repository.NewUserRepo(postgresDB, redis)
 	userUsecase := usecase.NewUserUsecase(userRepo)
+	ticketRepo := repository.NewTicketRepo(postgresDB, redis)
+	outboxRepo := repository.NewOutboxRepo(postgresDB)
+	outboxUsecase := usecase.NewOutboxUsecase(outboxRepo, unitOfWork, rmqChannel)
+	webhookSubscriptionRepo := repository.NewWebhookSubscriptionRepo(postgresDB)
+	webhookDeliveryRepo := repository.NewWebhookDeliveryRepo(postgresDB)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookSubscriptionRepo, webhookDeliveryRepo, outboxUsecase, unitOfWork)
+	ticketEventBroker := repository.NewTicketEventBroker(redis)
+	ticketEventUsecase := usecase.NewTicketEventUsecase(ticketEventBroker)
+	var eventOutbox model.IOutboxUsecase
+	if config.EventsPublishRabbitMQ() {
+		eventOutbox = outboxUsecase
+	}
+	eventBus := usecase.NewEventBus(eventOutbox, unitOfWork)
 	commentRepo := repository.NewCommentRepo(postgresDB)
-	commentUsecase := usecase.NewCommentUsecase(commentRepo)
+	commentUsecase := usecase.NewCommentUsecase(commentRepo, ticketRepo, eventBus, unitOfWork)
 	attachmentRepo := repository.NewAttachmentRepo(postgresDB)
-	attachmentUsecase := usecase.NewAttachmentUsecase(attachmentRepo)
+	blobStorage := newBlobStorage()
+	malwareScanner := newMalwareScanner()
+	attachmentUsecase := usecase.NewAttachmentUsecase(attachmentRepo, ticketRepo, blobStorage, malwareScanner, outboxUsecase, eventBus, unitOfWork)
 	ticketHistoryRepo := repository.NewTicketHistoryRepo(postgresDB, esClient)
-	ticketHistoryUsecase := usecase.NewTicketHistoryUsecase(ticketHistoryRepo)
+	ticketHistoryUsecase := usecase.NewTicketHistoryUsecase(ticketHistoryRepo, ticketRepo)
 	notificationRepo := repository.NewNotificationRepo(postgresDB)
-	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo, rmqChannel)
-	ticketRepo := repository.NewTicketRepo(postgresDB, redis)
+	notificationChannelRepo := repository.NewNotificationChannelRepo(postgresDB)
+	notificationPreferenceRepo := repository.NewNotificationPreferenceRepo(postgresDB)
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo, notificationChannelRepo, notificationPreferenceRepo, userRepo, ticketRepo, outboxUsecase, unitOfWork, emailTemplates, rmqConn)
+	businessCalendarRepo := repository.NewBusinessCalendarRepo(postgresDB)
+	businessCalendarUsecase := usecase.NewBusinessCalendarUsecase(businessCalendarRepo)
+	slaPolicyRepo := repository.NewSLAPolicyRepo(postgresDB)
+	slaPolicyUsecase := usecase.NewSLAPolicyUsecase(slaPolicyRepo, businessCalendarRepo)
+	ticketTransitionRepo := repository.NewTicketTransitionRepo(postgresDB)
+	ticketTransitionUsecase := usecase.NewTicketTransitionUsecase(ticketTransitionRepo)
 	ticketUsecase := usecase.NewTicketUsecase(
 		ticketRepo,
 		userRepo,
 		commentRepo,
 		attachmentRepo,
-		ticketHistoryRepo, notificationUsecase,
+		ticketHistoryRepo,
+		slaPolicyUsecase,
+		ticketTransitionUsecase,
+		eventBus,
+		unitOfWork,
 		rmqChannel,
 	)
+	// history rows and notifications roll back with the change that raised
+	// them; search indexing and pushes to clients wait for the commit
+	eventBus.Subscribe(model.DomainEventTicketCreated, ticketHistoryUsecase.Record)
+	eventBus.Subscribe(model.DomainEventTicketUpdated, ticketHistoryUsecase.Record)
+	eventBus.SubscribeAfterCommit(model.DomainEventTicketCreated, ticketHistoryUsecase.Index)
+	eventBus.SubscribeAfterCommit(model.DomainEventTicketUpdated, ticketHistoryUsecase.Index)
+	eventBus.Subscribe(model.DomainEventTicketCreated, notificationUsecase.NotifyTicketEvent)
+	eventBus.Subscribe(model.DomainEventTicketAssigned, notificationUsecase.NotifyTicketEvent)
+	eventBus.Subscribe(model.DomainEventTicketStatusChanged, notificationUsecase.NotifyTicketEvent)
+	eventBus.Subscribe(model.DomainEventCommentAdded, notificationUsecase.NotifyTicketEvent)
+	eventBus.Subscribe(model.DomainEventAttachmentInfected, notificationUsecase.NotifyTicketEvent)
+	eventBus.Subscribe(model.DomainEventTicketSLAAtRisk, notificationUsecase.NotifyTicketEvent)
+	eventBus.Subscribe(model.DomainEventCommentAdded, ticketUsecase.RecordFirstResponse)
+	for _, name := range model.DomainEventNames {
+		eventBus.Subscribe(name, webhookUsecase.Dispatch)
+		eventBus.SubscribeAfterCommit(name, ticketEventUsecase.Publish)
+	}
+
+	// email tickets need an assignee, so a gateway without one would turn
+	// every new message away
+	if (config.InboundMaildir() != "" || config.InboundSMTPAddr() != "") && config.InboundDefaultAssignee() == 0 {
+		log.Fatalf("inbound.default_assignee must be set when inbound email is enabled")
+	}
+
+	inboundMessageRepo := repository.NewInboundMessageRepo(postgresDB)
+	inboundEmailUsecase := usecase.NewInboundEmailUsecase(inboundMessageRepo, userRepo, ticketUsecase, commentUsecase, attachmentUsecase, unitOfWork)
 
 	e := echo.New()
 
-	handlerHttp.NewUserHandler(e, userUsecase)
-	handlerHttp.NewTicketHandler(e, ticketUsecase)
-	handlerHttp.NewCommentHandler(e, commentUsecase)
-	handlerHttp.NewAttachmentHandler(e, attachmentUsecase)
-	handlerHttp.NewTicketHistoryHandler(e, ticketHistoryUsecase)
-	handlerHttp.NewNotificationHandler(e, notificationUsecase)
+	authMiddleware := handlerHttp.NewAuthMiddleware(userUsecase)
+
+	handlerHttp.NewUserHandler(e, userUsecase, authMiddleware)
+	handlerHttp.NewTicketHandler(e, ticketUsecase, authMiddleware)
+	handlerHttp.NewTicketEventHandler(e, ticketEventUsecase, userUsecase, authMiddleware)
+	handlerHttp.NewCommentHandler(e, commentUsecase, authMiddleware)
+	handlerHttp.NewAttachmentHandler(e, attachmentUsecase, config.StorageMaxUploadSize(), authMiddleware)
+	handlerHttp.NewTicketHistoryHandler(e, ticketHistoryUsecase, authMiddleware)
+	handlerHttp.NewNotificationHandler(e, notificationUsecase, authMiddleware)
+	handlerHttp.NewSLAPolicyHandler(e, slaPolicyUsecase, authMiddleware)
+	handlerHttp.NewBusinessCalendarHandler(e, businessCalendarUsecase, authMiddleware)
+	handlerHttp.NewTicketTransitionHandler(e, ticketTransitionUsecase, authMiddleware)
+	handlerHttp.NewWebhookHandler(e, webhookUsecase, authMiddleware)
 
 	var wg sync.WaitGroup
 	errCh := make(chan error, 2)
@@ -89,7 +162,31 @@ func httpServer(cmd *cobra.Command, args []string) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		worker.StartEmailWorker(rmqChannel)
+		// consumers open channels of their own, so one channel error does not
+		// stop the others
+		worker.StartNotificationWorker(rmqConn, config.EmailQueue, notificationUsecase, worker.NewSMTPDriver(emailTemplates))
+		worker.StartNotificationWorker(rmqConn, config.WebhookQueue, notificationUsecase, worker.NewWebhookDriver())
+		worker.StartNotificationWorker(rmqConn, config.ChatQueue, notificationUsecase, worker.NewChatDriver(emailTemplates))
+		worker.StartWebhookDeliveryWorker(rmqConn, webhookUsecase)
+		worker.StartAttachmentScanWorker(rmqConn, attachmentUsecase)
+		worker.StartDigestWorker(notificationUsecase, config.NotificationDigestInterval())
+		worker.StartSLABreachWorker(ticketUsecase, config.SLAScanInterval())
+
+		if config.OutboxRelayInServer() {
+			go worker.RunOutboxRelay(context.Background(), func(ch *amqp.Channel) model.IOutboxUsecase {
+				return usecase.NewOutboxUsecase(outboxRepo, unitOfWork, ch)
+			}, false)
+		}
+
+		if dir := config.InboundMaildir(); dir != "" {
+			worker.StartMaildirWatcher(dir, config.InboundPollInterval(), config.InboundMaxMessageSize(), inboundEmailUsecase)
+		}
+		if addr := config.InboundSMTPAddr(); addr != "" {
+			err := worker.StartInboundSMTP(addr, config.InboundSMTPDomain(), config.InboundMaxMessageSize(), inboundEmailUsecase)
+			if err != nil {
+				log.Printf("Failed to start inbound SMTP listener: %v", err)
+			}
+		}
 
 		select {}
 	}()
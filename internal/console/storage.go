@@ -0,0 +1,45 @@
+package console
+
+import (
+	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/model"
+	"helpdesk-ticketing-system/internal/repository"
+	"log"
+)
+
+// newBlobStorage returns the attachment storage driver set in storage.driver.
+func newBlobStorage() model.IBlobStorage {
+	switch config.StorageDriver() {
+	case model.BlobStorageLocal:
+		return repository.NewLocalBlobStorage(config.StorageLocalDir())
+	case model.BlobStorageS3:
+		if config.StorageS3Endpoint() == "" || config.StorageS3Bucket() == "" {
+			log.Fatalf("storage.s3.endpoint and storage.s3.bucket are required by the s3 storage driver")
+		}
+
+		return repository.NewS3BlobStorage(repository.S3Config{
+			Endpoint:  config.StorageS3Endpoint(),
+			Region:    config.StorageS3Region(),
+			Bucket:    config.StorageS3Bucket(),
+			AccessKey: config.StorageS3AccessKey(),
+			SecretKey: config.StorageS3SecretKey(),
+			PathStyle: config.StorageS3PathStyle(),
+		})
+	}
+
+	log.Fatalf("Unknown storage driver %q", config.StorageDriver())
+	return nil
+}
+
+// newMalwareScanner returns the attachment scanner set in scanner.driver.
+func newMalwareScanner() model.IMalwareScanner {
+	switch config.ScannerDriver() {
+	case model.MalwareScannerClamd:
+		return repository.NewClamdScanner(config.ScannerClamdAddress(), config.ScannerClamdTimeout())
+	case model.MalwareScannerFake:
+		return repository.NewFakeScanner()
+	}
+
+	log.Fatalf("Unknown scanner driver %q", config.ScannerDriver())
+	return nil
+}
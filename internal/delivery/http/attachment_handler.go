@@ -1,28 +1,40 @@
 package http
 
 import (
-	"fmt"
+	"errors"
 	"helpdesk-ticketing-system/internal/model"
-	"io"
+	"mime"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
-	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+// multipartOverhead is allowed on top of the file size for the form fields
+// and part headers of an upload.
+const multipartOverhead = 1 << 20
+
 type AttachmentHandler struct {
 	attachmentUsecase model.IAttachmentUsecase
+	maxUploadSize     int64
 }
 
-func NewAttachmentHandler(e *echo.Echo, attachmentUsecase model.IAttachmentUsecase) {
-	handler := &AttachmentHandler{attachmentUsecase: attachmentUsecase}
+// NewAttachmentHandler registers the attachment routes. Upload request bodies
+// are cut off a little above maxUploadSize; 0 leaves them unlimited.
+func NewAttachmentHandler(e *echo.Echo, attachmentUsecase model.IAttachmentUsecase, maxUploadSize int64, auth echo.MiddlewareFunc) {
+	handler := &AttachmentHandler{
+		attachmentUsecase: attachmentUsecase,
+		maxUploadSize:     maxUploadSize,
+	}
 
 	routeUrl := e.Group("v1/attachment")
-	routeUrl.POST("/upload", handler.Upload, AuthMiddleware)
-	routeUrl.GET("/:ticket_id", handler.FindAllByTicketID, AuthMiddleware)
+	routeUrl.POST("/upload", handler.Upload, auth)
+	routeUrl.POST("/rescan", handler.RescanQuarantined, auth, RequireRole(model.RoleAdmin))
+	routeUrl.GET("/:ticket_id", handler.FindAllByTicketID, auth)
+	routeUrl.GET("/:id/download", handler.Download, auth)
+	routeUrl.POST("/:id/download-url", handler.SignDownloadURL, auth)
+	// the signature stands in for the access token
+	routeUrl.GET("/:id/download/signed", handler.DownloadSigned)
 }
 
 func (h *AttachmentHandler) FindAllByTicketID(ctx echo.Context) error {
@@ -32,6 +44,12 @@ func (h *AttachmentHandler) FindAllByTicketID(ctx echo.Context) error {
 	}
 
 	attachments, err := h.attachmentUsecase.FindAllByTicketID(ctx.Request().Context(), ticketID)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if errors.Is(err, model.ErrTicketNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Ticket not found")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch attachments")
 	}
@@ -43,6 +61,20 @@ func (h *AttachmentHandler) FindAllByTicketID(ctx echo.Context) error {
 }
 
 func (h *AttachmentHandler) Upload(ctx echo.Context) error {
+	if h.maxUploadSize > 0 {
+		req := ctx.Request()
+		req.Body = http.MaxBytesReader(ctx.Response(), req.Body, h.maxUploadSize+multipartOverhead)
+	}
+
+	_, err := ctx.MultipartForm()
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "File is too large")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid multipart form")
+	}
+
 	ticketIDStr := ctx.FormValue("ticket_id")
 	ticketID, err := strconv.ParseInt(ticketIDStr, 10, 64)
 	if err != nil || ticketID == 0 {
@@ -54,41 +86,143 @@ func (h *AttachmentHandler) Upload(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
 	}
 
-	if _, err := os.Stat("./uploads/tickets"); os.IsNotExist(err) {
-		os.MkdirAll("./uploads/tickets", os.ModePerm)
-	}
-
-	fileName := fmt.Sprintf("%d_%s", time.Now().UnixNano(), filepath.Base(file.Filename))
-	savePath := filepath.Join("./uploads/tickets", fileName)
-
 	src, err := file.Open()
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to open uploaded file")
 	}
 	defer src.Close()
 
-	dst, err := os.Create(savePath)
+	attachment, err := h.attachmentUsecase.Upload(ctx.Request().Context(), model.UploadAttachmentInput{
+		TicketID:    ticketID,
+		Filename:    file.Filename,
+		ContentType: file.Header.Get("Content-Type"),
+		Size:        file.Size,
+		Content:     src,
+	})
+	switch {
+	case errors.Is(err, model.ErrFileTooLarge):
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "File is too large")
+	case errors.Is(err, model.ErrFileTypeNotAllowed):
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "File type is not allowed")
+	case errors.Is(err, model.ErrTicketNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Ticket not found")
+	case errors.Is(err, model.ErrForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create attachment")
+	}
+
+	return ctx.JSON(http.StatusCreated, Response{
+		Status:  http.StatusCreated,
+		Message: "Attachment created successfully",
+		Data:    attachment,
+	})
+}
+
+func (h *AttachmentHandler) RescanQuarantined(ctx echo.Context) error {
+	var body model.RescanAttachmentsInput
+	if err := ctx.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	queued, err := h.attachmentUsecase.RescanQuarantined(ctx.Request().Context(), body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create destination file")
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to queue attachment scans")
 	}
-	defer dst.Close()
 
-	if _, err = io.Copy(dst, src); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to save file")
+	return ctx.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Attachment scans queued successfully",
+		Data:    map[string]int{"queued": queued},
+	})
+}
+
+func (h *AttachmentHandler) Download(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid id")
 	}
 
-	input := model.CreateAttachmentInput{
-		TicketID: ticketID,
-		FilePath: savePath,
+	download, err := h.attachmentUsecase.Download(ctx.Request().Context(), id)
+	if err != nil {
+		return downloadError(err)
 	}
 
-	err = h.attachmentUsecase.Create(ctx.Request().Context(), input)
+	return serveAttachment(ctx, download)
+}
+
+func (h *AttachmentHandler) SignDownloadURL(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create attachment")
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid id")
 	}
 
-	return ctx.JSON(http.StatusCreated, Response{
-		Status:  http.StatusCreated,
-		Message: "Attachment created successfully",
+	signed, err := h.attachmentUsecase.SignDownloadURL(ctx.Request().Context(), id)
+	if err != nil {
+		return downloadError(err)
+	}
+
+	return ctx.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   signed,
+	})
+}
+
+func (h *AttachmentHandler) DownloadSigned(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid id")
+	}
+
+	expires, err := strconv.ParseInt(ctx.QueryParam("expires"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid expires")
+	}
+
+	download, err := h.attachmentUsecase.DownloadSigned(ctx.Request().Context(), id, expires, ctx.QueryParam("signature"))
+	if err != nil {
+		return downloadError(err)
+	}
+
+	return serveAttachment(ctx, download)
+}
+
+func downloadError(err error) error {
+	switch {
+	case errors.Is(err, model.ErrForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	case errors.Is(err, model.ErrAttachmentQuarantined):
+		return echo.NewHTTPError(http.StatusConflict, "Attachment is waiting for its malware scan")
+	case errors.Is(err, model.ErrAttachmentInfected):
+		return echo.NewHTTPError(http.StatusForbidden, "Attachment is infected")
+	}
+
+	return echo.NewHTTPError(http.StatusNotFound, "Attachment not found")
+}
+
+// serveAttachment streams the attachment with http.ServeContent, which
+// answers Range and conditional requests. The file is always sent as a
+// download so uploaded HTML cannot run on our origin.
+func serveAttachment(ctx echo.Context, download *model.AttachmentDownload) error {
+	defer download.Content.Close()
+
+	header := ctx.Response().Header()
+	if download.ContentType != "" {
+		header.Set(echo.HeaderContentType, download.ContentType)
+	}
+	disposition := mime.FormatMediaType("attachment", map[string]string{
+		"filename": download.Attachment.Filename,
 	})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	header.Set(echo.HeaderContentDisposition, disposition)
+	header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+	header.Set("Cache-Control", "private")
+
+	http.ServeContent(ctx.Response(), ctx.Request(), download.Attachment.Filename, download.ModTime, download.Content)
+	return nil
 }
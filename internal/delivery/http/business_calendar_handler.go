@@ -0,0 +1,127 @@
+package http
+
+import (
+	"errors"
+	"helpdesk-ticketing-system/internal/model"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BusinessCalendarHandler struct {
+	businessCalendarUsecase model.IBusinessCalendarUsecase
+}
+
+func NewBusinessCalendarHandler(e *echo.Echo, businessCalendarUsecase model.IBusinessCalendarUsecase, auth echo.MiddlewareFunc) {
+	handler := &BusinessCalendarHandler{businessCalendarUsecase: businessCalendarUsecase}
+
+	routeUrl := e.Group("v1/business-calendar", auth, RequireRole(model.RoleAdmin))
+	routeUrl.GET("", handler.FindAll)
+	routeUrl.GET("/:id", handler.FindById)
+	routeUrl.POST("/create", handler.Create)
+	routeUrl.PUT("/update/:id", handler.Update)
+	routeUrl.DELETE("/delete/:id", handler.Delete)
+}
+
+func (h *BusinessCalendarHandler) FindAll(c echo.Context) error {
+	calendars, err := h.businessCalendarUsecase.FindAll(c.Request().Context())
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch business calendars")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   calendars,
+	})
+}
+
+func (h *BusinessCalendarHandler) FindById(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid business calendar ID format")
+	}
+
+	calendar, err := h.businessCalendarUsecase.FindById(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Business calendar not found")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   calendar,
+	})
+}
+
+func (h *BusinessCalendarHandler) Create(c echo.Context) error {
+	var body model.CreateBusinessCalendarInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	calendar, err := h.businessCalendarUsecase.Create(c.Request().Context(), body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, Response{
+		Status:  http.StatusCreated,
+		Message: "Business calendar created successfully",
+		Data:    calendar,
+	})
+}
+
+func (h *BusinessCalendarHandler) Update(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid business calendar ID format")
+	}
+
+	var body model.UpdateBusinessCalendarInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	calendar, err := h.businessCalendarUsecase.Update(c.Request().Context(), id, body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Business calendar updated successfully",
+		Data:    calendar,
+	})
+}
+
+func (h *BusinessCalendarHandler) Delete(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid business calendar ID format")
+	}
+
+	err = h.businessCalendarUsecase.Delete(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete business calendar")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Business calendar deleted successfully",
+	})
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"helpdesk-ticketing-system/internal/model"
 	"net/http"
 	"strconv"
@@ -12,19 +13,22 @@ type CommentHandler struct {
 	commentUsecase model.ICommentUsecase
 }
 
-func NewCommentHandler(e *echo.Echo, commentUsecase model.ICommentUsecase) {
+func NewCommentHandler(e *echo.Echo, commentUsecase model.ICommentUsecase, auth echo.MiddlewareFunc) {
 	handler := &CommentHandler{commentUsecase: commentUsecase}
 
 	routeUrl := e.Group("v1/comment")
-	routeUrl.GET("", handler.FindAll, AuthMiddleware)
-	routeUrl.GET("/:id", handler.FindById, AuthMiddleware)
-	routeUrl.POST("/create", handler.Create, AuthMiddleware)
-	routeUrl.PUT("/update/:id", handler.Update, AuthMiddleware)
-	routeUrl.DELETE("/delete/:id", handler.Delete, AuthMiddleware)
+	routeUrl.GET("", handler.FindAll, auth)
+	routeUrl.GET("/:id", handler.FindById, auth)
+	routeUrl.POST("/create", handler.Create, auth)
+	routeUrl.PUT("/update/:id", handler.Update, auth)
+	routeUrl.DELETE("/delete/:id", handler.Delete, auth)
 }
 
 func (c *CommentHandler) FindAll(ctx echo.Context) error {
 	comments, err := c.commentUsecase.FindAll(ctx.Request().Context(), model.Comment{})
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -44,6 +48,9 @@ func (c *CommentHandler) FindById(ctx echo.Context) error {
 	}
 
 	comment, err := c.commentUsecase.FindById(ctx.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "Comment not found")
 	}
@@ -61,6 +68,12 @@ func (c *CommentHandler) Create(ctx echo.Context) error {
 	}
 
 	comment, err := c.commentUsecase.Create(ctx.Request().Context(), body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if errors.Is(err, model.ErrTicketNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Ticket not found")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create comment")
 	}
@@ -86,6 +99,9 @@ func (c *CommentHandler) Update(ctx echo.Context) error {
 	}
 
 	comment, err := c.commentUsecase.Update(ctx.Request().Context(), id, body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update comment")
 	}
@@ -106,6 +122,9 @@ func (c *CommentHandler) Delete(ctx echo.Context) error {
 	}
 
 	err = c.commentUsecase.Delete(ctx.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete comment")
 	}
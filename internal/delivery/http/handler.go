@@ -1,8 +1,10 @@
 package http
 
 type Response struct {
-	Status      any         `json:"status,omitempty"`
-	Message     string      `json:"message,omitempty"`
-	Data        interface{} `json:"data,omitempty"`
-	AccessToken string      `json:"access_token,omitempty"`
+	Status       any         `json:"status,omitempty"`
+	Message      string      `json:"message,omitempty"`
+	Data         interface{} `json:"data,omitempty"`
+	Meta         interface{} `json:"meta,omitempty"`
+	AccessToken  string      `json:"access_token,omitempty"`
+	RefreshToken string      `json:"refresh_token,omitempty"`
 }
@@ -12,31 +12,99 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		authHeader := c.Request().Header.Get(echo.HeaderAuthorization)
-		if authHeader == "" {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Missing token")
+type accessTokenKey struct{}
+
+// NewAuthMiddleware decodes the bearer token and checks it against the
+// session store, so tokens stop working as soon as they are logged out.
+func NewAuthMiddleware(userUsecase model.IUserUsecase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			accessToken, err := bearerToken(c)
+			if err != nil {
+				return err
+			}
+
+			var claim model.CustomClaims
+			err = helper.DecodeToken(accessToken, &claim)
+			if err != nil {
+				log.Println("Token decoding failed:", err)
+				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired token")
+			}
+
+			session, err := userUsecase.ValidateSession(c.Request().Context(), accessToken)
+			if err != nil || session.UserID != claim.UserID {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Session revoked or expired")
+			}
+
+			ctx := context.WithValue(c.Request().Context(), model.BearerAuthKey, claim)
+			ctx = context.WithValue(ctx, accessTokenKey{}, accessToken)
+			req := c.Request().WithContext(ctx)
+			c.SetRequest(req)
+
+			return next(c)
 		}
+	}
+}
+
+// NewStreamTicketMiddleware lets clients that cannot set headers, such as
+// EventSource, authenticate with a stream ticket in the stream_ticket query
+// parameter. It must run before the auth middleware. Tickets work once and
+// expire within seconds, so unlike access tokens they are harmless in
+// access logs and browser history.
+func NewStreamTicketMiddleware(userUsecase model.IUserUsecase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ticket := c.QueryParam("stream_ticket")
+			if ticket == "" || c.Request().Header.Get(echo.HeaderAuthorization) != "" {
+				return next(c)
+			}
+
+			accessToken, err := userUsecase.RedeemStreamTicket(c.Request().Context(), ticket)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired stream ticket")
+			}
 
-		splitAuth := strings.Split(authHeader, " ")
-		if len(splitAuth) != 2 || splitAuth[0] != "Bearer" {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token format")
+			c.Request().Header.Set(echo.HeaderAuthorization, "Bearer "+accessToken)
+			return next(c)
 		}
+	}
+}
+
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claim, ok := c.Request().Context().Value(model.BearerAuthKey).(model.CustomClaims)
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+			}
 
-		accessToken := splitAuth[1]
+			for _, role := range roles {
+				if claim.Role == role {
+					return next(c)
+				}
+			}
 
-		var claim model.CustomClaims
-		err := helper.DecodeToken(accessToken, &claim)
-		if err != nil {
-			log.Println("Token decoding failed:", err)
-			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired token")
+			return echo.NewHTTPError(http.StatusForbidden, "Access denied")
 		}
+	}
+}
 
-		ctx := context.WithValue(c.Request().Context(), model.BearerAuthKey, claim)
-		req := c.Request().WithContext(ctx)
-		c.SetRequest(req)
+func bearerToken(c echo.Context) (string, error) {
+	authHeader := c.Request().Header.Get(echo.HeaderAuthorization)
+	if authHeader == "" {
+		return "", echo.NewHTTPError(http.StatusUnauthorized, "Missing token")
+	}
 
-		return next(c)
+	splitAuth := strings.Split(authHeader, " ")
+	if len(splitAuth) != 2 || splitAuth[0] != "Bearer" {
+		return "", echo.NewHTTPError(http.StatusUnauthorized, "Invalid token format")
 	}
+
+	return splitAuth[1], nil
+}
+
+// requestToken returns the raw token accepted by the auth middleware.
+func requestToken(c echo.Context) string {
+	token, _ := c.Request().Context().Value(accessTokenKey{}).(string)
+	return token
 }
@@ -1,8 +1,10 @@
 package http
 
 import (
+	"errors"
 	"helpdesk-ticketing-system/internal/model"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 )
@@ -11,11 +13,82 @@ type NotificationHandler struct {
 	notificationUsecase model.INotificationUsecase
 }
 
-func NewNotificationHandler(e *echo.Echo, notificationUsecase model.INotificationUsecase) {
+func NewNotificationHandler(e *echo.Echo, notificationUsecase model.INotificationUsecase, auth echo.MiddlewareFunc) {
 	handler := &NotificationHandler{notificationUsecase: notificationUsecase}
 
 	routeUrl := e.Group("v1/notification")
-	routeUrl.POST("/send", handler.Send, AuthMiddleware)
+	routeUrl.GET("", handler.FindInbox, auth)
+	routeUrl.POST("/:id/read", handler.MarkRead, auth)
+	routeUrl.POST("/read-all", handler.MarkAllRead, auth)
+	// sends to any address, so it is kept to staff; SendNotification itself
+	// is also called by the system without claims
+	routeUrl.POST("/send", handler.Send, auth, RequireRole(model.RoleAdmin, model.RoleSupport))
+	routeUrl.GET("/channels", handler.FindChannels, auth)
+	routeUrl.POST("/channels", handler.CreateChannel, auth)
+	routeUrl.DELETE("/channels/:id", handler.DeleteChannel, auth)
+	routeUrl.GET("/preferences", handler.GetPreferences, auth)
+	routeUrl.PUT("/preferences", handler.UpdatePreferences, auth)
+	routeUrl.POST("/dead-letters/requeue", handler.RequeueDeadLetters, auth, RequireRole(model.RoleAdmin))
+	routeUrl.POST("/templates/preview", handler.PreviewTemplate, auth, RequireRole(model.RoleAdmin))
+}
+
+func (n *NotificationHandler) FindInbox(c echo.Context) error {
+	var param model.NotificationInboxParam
+	err := echo.QueryParamsBinder(c).
+		Int64("limit", &param.Limit).
+		String("cursor", &param.Cursor).
+		Bool("unread", &param.UnreadOnly).
+		BindError()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid query parameters")
+	}
+
+	page, err := n.notificationUsecase.FindInbox(c.Request().Context(), param)
+	if errors.Is(err, model.ErrInvalidFilter) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch notifications")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   page.Notifications,
+		Meta:   page.Meta,
+	})
+}
+
+func (n *NotificationHandler) MarkRead(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid notification ID format")
+	}
+
+	err = n.notificationUsecase.MarkRead(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Notification not found")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Notification marked as read",
+	})
+}
+
+func (n *NotificationHandler) MarkAllRead(c echo.Context) error {
+	count, err := n.notificationUsecase.MarkAllRead(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to mark notifications as read")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Notifications marked as read",
+		Data:    map[string]int64{"marked": count},
+	})
 }
 
 func (n *NotificationHandler) Send(c echo.Context) error {
@@ -34,3 +107,128 @@ func (n *NotificationHandler) Send(c echo.Context) error {
 		Message: "Notification sent successfully",
 	})
 }
+
+func (n *NotificationHandler) RequeueDeadLetters(c echo.Context) error {
+	var body model.RequeueDeadLettersInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	requeued, err := n.notificationUsecase.RequeueDeadLetters(c.Request().Context(), body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to requeue dead letters")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Dead letters requeued successfully",
+		Data:    map[string]int{"requeued": requeued},
+	})
+}
+
+func (n *NotificationHandler) PreviewTemplate(c echo.Context) error {
+	var body model.PreviewTemplateInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	email, err := n.notificationUsecase.PreviewTemplate(c.Request().Context(), body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// ?format=html shows the HTML part the way a mail client would
+	if c.QueryParam("format") == "html" {
+		return c.HTML(http.StatusOK, email.HTML)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   email,
+	})
+}
+
+func (n *NotificationHandler) FindChannels(c echo.Context) error {
+	settings, err := n.notificationUsecase.FindChannels(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch notification channels")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   settings,
+	})
+}
+
+func (n *NotificationHandler) CreateChannel(c echo.Context) error {
+	var body model.CreateNotificationChannelInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	setting, err := n.notificationUsecase.CreateChannel(c.Request().Context(), body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, Response{
+		Status: http.StatusCreated,
+		Data:   setting,
+	})
+}
+
+func (n *NotificationHandler) DeleteChannel(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid notification channel ID format")
+	}
+
+	err = n.notificationUsecase.DeleteChannel(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Notification channel not found")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Notification channel deleted successfully",
+	})
+}
+
+func (n *NotificationHandler) GetPreferences(c echo.Context) error {
+	preferences, err := n.notificationUsecase.GetPreferences(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch notification preferences")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   preferences,
+	})
+}
+
+func (n *NotificationHandler) UpdatePreferences(c echo.Context) error {
+	var body model.UpdateNotificationPreferencesInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	preferences, err := n.notificationUsecase.UpdatePreferences(c.Request().Context(), body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Notification preferences updated successfully",
+		Data:    preferences,
+	})
+}
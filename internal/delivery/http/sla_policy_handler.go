@@ -0,0 +1,127 @@
+package http
+
+import (
+	"errors"
+	"helpdesk-ticketing-system/internal/model"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SLAPolicyHandler struct {
+	slaPolicyUsecase model.ISLAPolicyUsecase
+}
+
+func NewSLAPolicyHandler(e *echo.Echo, slaPolicyUsecase model.ISLAPolicyUsecase, auth echo.MiddlewareFunc) {
+	handler := &SLAPolicyHandler{slaPolicyUsecase: slaPolicyUsecase}
+
+	routeUrl := e.Group("v1/sla-policy", auth, RequireRole(model.RoleAdmin))
+	routeUrl.GET("", handler.FindAll)
+	routeUrl.GET("/:id", handler.FindById)
+	routeUrl.POST("/create", handler.Create)
+	routeUrl.PUT("/update/:id", handler.Update)
+	routeUrl.DELETE("/delete/:id", handler.Delete)
+}
+
+func (h *SLAPolicyHandler) FindAll(c echo.Context) error {
+	policies, err := h.slaPolicyUsecase.FindAll(c.Request().Context())
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch SLA policies")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   policies,
+	})
+}
+
+func (h *SLAPolicyHandler) FindById(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid SLA policy ID format")
+	}
+
+	policy, err := h.slaPolicyUsecase.FindById(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "SLA policy not found")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   policy,
+	})
+}
+
+func (h *SLAPolicyHandler) Create(c echo.Context) error {
+	var body model.CreateSLAPolicyInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	policy, err := h.slaPolicyUsecase.Create(c.Request().Context(), body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, Response{
+		Status:  http.StatusCreated,
+		Message: "SLA policy created successfully",
+		Data:    policy,
+	})
+}
+
+func (h *SLAPolicyHandler) Update(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid SLA policy ID format")
+	}
+
+	var body model.UpdateSLAPolicyInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	policy, err := h.slaPolicyUsecase.Update(c.Request().Context(), id, body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "SLA policy updated successfully",
+		Data:    policy,
+	})
+}
+
+func (h *SLAPolicyHandler) Delete(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid SLA policy ID format")
+	}
+
+	err = h.slaPolicyUsecase.Delete(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete SLA policy")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "SLA policy deleted successfully",
+	})
+}
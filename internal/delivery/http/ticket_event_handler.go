@@ -0,0 +1,108 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"helpdesk-ticketing-system/internal/model"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ticketEventHeartbeat keeps idle streams open through proxies that close
+// silent connections.
+const ticketEventHeartbeat = 25 * time.Second
+
+type TicketEventHandler struct {
+	ticketEventUsecase model.ITicketEventUsecase
+	userUsecase        model.IUserUsecase
+}
+
+func NewTicketEventHandler(e *echo.Echo, ticketEventUsecase model.ITicketEventUsecase, userUsecase model.IUserUsecase, auth echo.MiddlewareFunc) {
+	handler := &TicketEventHandler{
+		ticketEventUsecase: ticketEventUsecase,
+		userUsecase:        userUsecase,
+	}
+
+	e.POST("v1/ticket/events/ticket", handler.IssueStreamTicket, auth)
+	e.GET("v1/ticket/events", handler.Stream, NewStreamTicketMiddleware(userUsecase), auth)
+}
+
+// IssueStreamTicket returns a single-use ticket for opening the event stream
+// from clients that cannot set the Authorization header.
+func (h *TicketEventHandler) IssueStreamTicket(c echo.Context) error {
+	ticket, err := h.userUsecase.IssueStreamTicket(c.Request().Context(), requestToken(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to issue stream ticket")
+	}
+
+	return c.JSON(http.StatusCreated, Response{
+		Status: http.StatusCreated,
+		Data:   ticket,
+	})
+}
+
+// Stream sends ticket events as Server-Sent Events until the client
+// disconnects. ticket_id limits the stream to a single ticket. The session
+// is checked again on every heartbeat, and the stream ends with a
+// session_expired event once it has been revoked or has expired.
+func (h *TicketEventHandler) Stream(c echo.Context) error {
+	var ticketID int64
+	err := echo.QueryParamsBinder(c).Int64("ticket_id", &ticketID).BindError()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ticket ID format")
+	}
+
+	ctx := c.Request().Context()
+	events, err := h.ticketEventUsecase.Subscribe(ctx, ticketID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to subscribe to ticket events")
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	heartbeat := time.NewTicker(ticketEventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			_, err := h.userUsecase.ValidateSession(ctx, requestToken(c))
+			if err != nil {
+				fmt.Fprint(res, "event: session_expired\ndata: {}\n\n")
+				res.Flush()
+				return nil
+			}
+
+			_, err = fmt.Fprint(res, ": ping\n\n")
+			if err != nil {
+				return nil
+			}
+			res.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+
+			_, err = fmt.Fprintf(res, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+			if err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package http
 
 import (
+	"errors"
 	"helpdesk-ticketing-system/internal/model"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -12,27 +15,35 @@ type TicketHandler struct {
 	ticketUsecase model.ITicketUsecase
 }
 
-func NewTicketHandler(e *echo.Echo, ticketUsecase model.ITicketUsecase) {
+func NewTicketHandler(e *echo.Echo, ticketUsecase model.ITicketUsecase, auth echo.MiddlewareFunc) {
 	handler := &TicketHandler{ticketUsecase: ticketUsecase}
 
 	routeUrl := e.Group("v1/ticket")
-	routeUrl.GET("", handler.FindAll, AuthMiddleware)
-	routeUrl.GET("/:id", handler.FindById, AuthMiddleware)
-	routeUrl.POST("/create", handler.Create, AuthMiddleware)
-	routeUrl.PUT("/update/:id", handler.Update, AuthMiddleware)
-	routeUrl.DELETE("/delete/:id", handler.Delete, AuthMiddleware)
+	routeUrl.GET("", handler.FindAll, auth)
+	routeUrl.GET("/:id", handler.FindById, auth)
+	routeUrl.POST("/create", handler.Create, auth)
+	routeUrl.PUT("/update/:id", handler.Update, auth)
+	routeUrl.DELETE("/delete/:id", handler.Delete, auth, RequireRole(model.RoleAdmin))
 }
 
 func (h *TicketHandler) FindAll(c echo.Context) error {
-	tickets, err := h.ticketUsecase.FindAll(c.Request().Context(), model.FindAllParam{})
+	filter, err := bindTicketFilter(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid query parameters")
+	}
 
+	page, err := h.ticketUsecase.FindAll(c.Request().Context(), filter)
+	if errors.Is(err, model.ErrInvalidFilter) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
 	return c.JSON(http.StatusOK, Response{
 		Status: http.StatusOK,
-		Data:   tickets,
+		Data:   page.Tickets,
+		Meta:   page.Meta,
 	})
 }
 
@@ -44,6 +55,9 @@ func (h *TicketHandler) FindById(c echo.Context) error {
 	}
 
 	ticket, err := h.ticketUsecase.FindById(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "Ticket not found")
 	}
@@ -61,6 +75,9 @@ func (h *TicketHandler) Create(c echo.Context) error {
 	}
 
 	ticket, err := h.ticketUsecase.Create(c.Request().Context(), body)
+	if errors.Is(err, model.ErrInvalidTransition) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create ticket")
 	}
@@ -84,6 +101,12 @@ func (h *TicketHandler) Update(c echo.Context) error {
 	}
 
 	ticket, err := h.ticketUsecase.Update(c.Request().Context(), id, body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if errors.Is(err, model.ErrInvalidTransition) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update ticket")
 	}
@@ -103,6 +126,9 @@ func (h *TicketHandler) Delete(c echo.Context) error {
 	}
 
 	err = h.ticketUsecase.Delete(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete ticket")
 	}
@@ -112,3 +138,55 @@ func (h *TicketHandler) Delete(c echo.Context) error {
 		Message: "Ticket deleted successfully",
 	})
 }
+
+// bindTicketFilter reads the ticket list query string. Dates are RFC 3339 and
+// include is a comma separated list of user, comments and attachments.
+func bindTicketFilter(c echo.Context) (model.FindAllParam, error) {
+	var filter model.FindAllParam
+	var createdFrom, createdTo, dueFrom, dueTo time.Time
+
+	err := echo.QueryParamsBinder(c).
+		Int64("limit", &filter.Limit).
+		String("cursor", &filter.Cursor).
+		String("status", &filter.Status).
+		String("priority", &filter.Priority).
+		Int64("assigned_to", &filter.AssignedTo).
+		Int64("user_id", &filter.UserID).
+		Time("created_from", &createdFrom, time.RFC3339).
+		Time("created_to", &createdTo, time.RFC3339).
+		Time("due_from", &dueFrom, time.RFC3339).
+		Time("due_to", &dueTo, time.RFC3339).
+		Bool("overdue", &filter.OverdueOnly).
+		String("q", &filter.Search).
+		String("sort", &filter.SortBy).
+		String("order", &filter.SortOrder).
+		BindError()
+	if err != nil {
+		return filter, err
+	}
+
+	// include= with no value embeds nothing; leaving it out embeds everything
+	if c.QueryParams().Has("include") {
+		filter.Include = []string{}
+		for _, name := range strings.Split(c.QueryParam("include"), ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				filter.Include = append(filter.Include, name)
+			}
+		}
+	}
+
+	filter.CreatedFrom = optionalTime(createdFrom)
+	filter.CreatedTo = optionalTime(createdTo)
+	filter.DueFrom = optionalTime(dueFrom)
+	filter.DueTo = optionalTime(dueTo)
+
+	return filter, nil
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
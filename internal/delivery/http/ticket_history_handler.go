@@ -1,26 +1,29 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"helpdesk-ticketing-system/internal/model"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 type TicketHistoryHandler struct {
 	ticketHistoryUsecase model.ITicketHistoryUsecase
 }
 
-func NewTicketHistoryHandler(e *echo.Echo, ticketHistoryUsecase model.ITicketHistoryUsecase) {
+func NewTicketHistoryHandler(e *echo.Echo, ticketHistoryUsecase model.ITicketHistoryUsecase, auth echo.MiddlewareFunc) {
 	handler := &TicketHistoryHandler{ticketHistoryUsecase: ticketHistoryUsecase}
 
 	routeUrl := e.Group("v1/ticket/history")
-	routeUrl.GET("/id/:id", handler.GetByTicketID, AuthMiddleware)
-	routeUrl.GET("/status/:status", handler.GetByStatus, AuthMiddleware)
-	routeUrl.GET("/priority/:priority", handler.GetByPriority, AuthMiddleware)
-	routeUrl.GET("/user/:id", handler.GetByUserID, AuthMiddleware)
+	routeUrl.GET("/id/:id", handler.GetByTicketID, auth)
+	routeUrl.GET("/status/:status", handler.GetByStatus, auth)
+	routeUrl.GET("/priority/:priority", handler.GetByPriority, auth)
+	routeUrl.GET("/user/:id", handler.GetByUserID, auth)
+	routeUrl.GET("/paused/:id", handler.GetPausedIntervals, auth)
 }
 
 func (t *TicketHistoryHandler) GetByTicketID(ctx echo.Context) error {
@@ -31,6 +34,9 @@ func (t *TicketHistoryHandler) GetByTicketID(ctx echo.Context) error {
 	}
 
 	history, err := t.ticketHistoryUsecase.GetTicketID(ctx.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "Ticket not found")
 	}
@@ -45,6 +51,9 @@ func (t *TicketHistoryHandler) GetByStatus(c echo.Context) error {
 	status := c.Param("status")
 
 	histories, err := t.ticketHistoryUsecase.GetStatus(c.Request().Context(), status)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "Ticket not found")
 	}
@@ -59,6 +68,9 @@ func (h *TicketHistoryHandler) GetByPriority(c echo.Context) error {
 	priority := c.Param("priority")
 
 	histories, err := h.ticketHistoryUsecase.GetPriority(c.Request().Context(), priority)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "Ticket not found")
 	}
@@ -77,6 +89,9 @@ func (t *TicketHistoryHandler) GetByUserID(c echo.Context) error {
 	}
 
 	histories, err := t.ticketHistoryUsecase.GetUserID(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "Ticket not found")
 	}
@@ -86,3 +101,27 @@ func (t *TicketHistoryHandler) GetByUserID(c echo.Context) error {
 		Data:   histories,
 	})
 }
+
+func (t *TicketHistoryHandler) GetPausedIntervals(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ticket ID")
+	}
+
+	intervals, err := t.ticketHistoryUsecase.GetPausedIntervals(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if errors.Is(err, model.ErrTicketNotFound) || errors.Is(err, gorm.ErrRecordNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Ticket not found")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch paused intervals")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   intervals,
+	})
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"errors"
+	"helpdesk-ticketing-system/internal/model"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type TicketTransitionHandler struct {
+	ticketTransitionUsecase model.ITicketTransitionUsecase
+}
+
+func NewTicketTransitionHandler(e *echo.Echo, ticketTransitionUsecase model.ITicketTransitionUsecase, auth echo.MiddlewareFunc) {
+	handler := &TicketTransitionHandler{ticketTransitionUsecase: ticketTransitionUsecase}
+
+	routeUrl := e.Group("v1/ticket-transition")
+	routeUrl.GET("", handler.FindAll, auth)
+	routeUrl.POST("/create", handler.Create, auth, RequireRole(model.RoleAdmin))
+	routeUrl.PUT("/update/:id", handler.Update, auth, RequireRole(model.RoleAdmin))
+	routeUrl.DELETE("/delete/:id", handler.Delete, auth, RequireRole(model.RoleAdmin))
+}
+
+func (h *TicketTransitionHandler) FindAll(c echo.Context) error {
+	transitions, err := h.ticketTransitionUsecase.FindAll(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch ticket transitions")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   transitions,
+	})
+}
+
+func (h *TicketTransitionHandler) Create(c echo.Context) error {
+	var body model.CreateTicketTransitionInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	transition, err := h.ticketTransitionUsecase.Create(c.Request().Context(), body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, Response{
+		Status:  http.StatusCreated,
+		Message: "Ticket transition created successfully",
+		Data:    transition,
+	})
+}
+
+func (h *TicketTransitionHandler) Update(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ticket transition ID format")
+	}
+
+	var body model.UpdateTicketTransitionInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	transition, err := h.ticketTransitionUsecase.Update(c.Request().Context(), id, body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Ticket transition not found")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Ticket transition updated successfully",
+		Data:    transition,
+	})
+}
+
+func (h *TicketTransitionHandler) Delete(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid ticket transition ID format")
+	}
+
+	err = h.ticketTransitionUsecase.Delete(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Ticket transition not found")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete ticket transition")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Ticket transition deleted successfully",
+	})
+}
@@ -1,7 +1,7 @@
 package http
 
 import (
-	"log"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -14,19 +14,22 @@ type UserHandler struct {
 	userUsecase model.IUserUsecase
 }
 
-func NewUserHandler(e *echo.Echo, userUsecase model.IUserUsecase) {
+func NewUserHandler(e *echo.Echo, userUsecase model.IUserUsecase, auth echo.MiddlewareFunc) {
 	handlers := &UserHandler{
 		userUsecase: userUsecase,
 	}
 
 	routeUser := e.Group("v1/auth")
 	routeUser.POST("/login", handlers.Login)
-	routeUser.POST("/logout", handlers.Logout, AuthMiddleware)
-	routeUser.GET("/user/:id", handlers.FindById, AuthMiddleware)
-	routeUser.GET("/users", handlers.FindAll, AuthMiddleware)
+	routeUser.POST("/refresh", handlers.Refresh)
+	routeUser.POST("/logout", handlers.Logout, auth)
+	routeUser.POST("/logout-all", handlers.LogoutAll, auth)
+	routeUser.GET("/user/:id", handlers.FindById, auth)
+	routeUser.GET("/users", handlers.FindAll, auth, RequireRole(model.RoleAdmin))
 	routeUser.POST("/register", handlers.Create)
-	routeUser.PUT("/update/:id", handlers.Update, AuthMiddleware)
-	routeUser.DELETE("/delete/:id", handlers.Delete, AuthMiddleware)
+	routeUser.POST("/user", handlers.Create, auth, RequireRole(model.RoleAdmin))
+	routeUser.PUT("/update/:id", handlers.Update, auth)
+	routeUser.DELETE("/delete/:id", handlers.Delete, auth, RequireRole(model.RoleAdmin))
 }
 
 func (handler *UserHandler) Login(c echo.Context) error {
@@ -35,20 +38,43 @@ func (handler *UserHandler) Login(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
 	}
 
-	accessToken, err := handler.userUsecase.Login(c.Request().Context(), body)
+	tokens, err := handler.userUsecase.Login(c.Request().Context(), body)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid Email or Password")
 	}
 
 	return c.JSON(http.StatusOK, Response{
-		Status:      http.StatusOK,
-		Message:     "Login successful",
-		AccessToken: accessToken,
+		Status:       http.StatusOK,
+		Message:      "Login successful",
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	})
+}
+
+func (handler *UserHandler) Refresh(c echo.Context) error {
+	var body model.RefreshTokenInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	tokens, err := handler.userUsecase.Refresh(c.Request().Context(), body)
+	if errors.Is(err, model.ErrRefreshTokenReused) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Refresh token reuse detected, please login again")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired refresh token")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:       http.StatusOK,
+		Message:      "Token refreshed",
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 	})
 }
 
 func (handler *UserHandler) Logout(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
+	token := requestToken(c)
 	if token == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "Missing token")
 	}
@@ -64,25 +90,34 @@ func (handler *UserHandler) Logout(c echo.Context) error {
 	})
 }
 
-func (handler *UserHandler) FindById(c echo.Context) error {
-	idParam := c.Param("id")
-	id, err := strconv.ParseInt(idParam, 10, 64)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID format")
-	}
-
+func (handler *UserHandler) LogoutAll(c echo.Context) error {
 	claim, ok := c.Request().Context().Value(model.BearerAuthKey).(model.CustomClaims)
 	if !ok {
 		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
 	}
 
-	if claim.UserID != id {
-		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	err := handler.userUsecase.LogoutAll(c.Request().Context(), claim.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to logout from all devices")
 	}
 
-	log.Printf("Authenticated User ID: %d", claim.UserID)
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Logged out from all devices",
+	})
+}
+
+func (handler *UserHandler) FindById(c echo.Context) error {
+	idParam := c.Param("id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID format")
+	}
 
 	user, err := handler.userUsecase.FindById(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "User not found")
 	}
@@ -99,6 +134,9 @@ func (handler *UserHandler) FindAll(c echo.Context) error {
 	filter.Email = c.QueryParam("email")
 
 	users, err := handler.userUsecase.FindAll(c.Request().Context(), filter)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch users")
 	}
@@ -119,15 +157,19 @@ func (handler *UserHandler) Create(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "All fields are required")
 	}
 
-	accessToken, err := handler.userUsecase.Create(c.Request().Context(), body)
+	tokens, err := handler.userUsecase.Create(c.Request().Context(), body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create user")
 	}
 
 	return c.JSON(http.StatusCreated, Response{
-		Status:      http.StatusCreated,
-		Message:     "User registered successfully",
-		AccessToken: accessToken,
+		Status:       http.StatusCreated,
+		Message:      "User registered successfully",
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 	})
 }
 
@@ -138,22 +180,16 @@ func (handler *UserHandler) Update(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID format")
 	}
 
-	claim, ok := c.Request().Context().Value(model.BearerAuthKey).(model.CustomClaims)
-	if !ok {
-		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
-	}
-
-	// User hanya boleh mengupdate datanya sendiri
-	if claim.UserID != id {
-		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
-	}
-
 	var body model.UpdateUserInput
 	if err := c.Bind(&body); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
 	}
 
+	// User hanya boleh mengupdate datanya sendiri, kecuali admin
 	err = handler.userUsecase.Update(c.Request().Context(), id, body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update user")
 	}
@@ -171,16 +207,10 @@ func (handler *UserHandler) Delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID format")
 	}
 
-	claim, ok := c.Request().Context().Value(model.BearerAuthKey).(model.CustomClaims)
-	if !ok {
-		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
-	}
-
-	if claim.UserID != id {
+	err = handler.userUsecase.Delete(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
 		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
 	}
-
-	err = handler.userUsecase.Delete(c.Request().Context(), id)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete user")
 	}
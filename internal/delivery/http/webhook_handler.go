@@ -0,0 +1,166 @@
+package http
+
+import (
+	"errors"
+	"helpdesk-ticketing-system/internal/model"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WebhookHandler struct {
+	webhookUsecase model.IWebhookUsecase
+}
+
+func NewWebhookHandler(e *echo.Echo, webhookUsecase model.IWebhookUsecase, auth echo.MiddlewareFunc) {
+	handler := &WebhookHandler{webhookUsecase: webhookUsecase}
+
+	routeUrl := e.Group("v1/webhook", auth, RequireRole(model.RoleAdmin))
+	routeUrl.GET("/subscriptions", handler.FindSubscriptions)
+	routeUrl.POST("/subscriptions", handler.CreateSubscription)
+	routeUrl.PUT("/subscriptions/:id", handler.UpdateSubscription)
+	routeUrl.DELETE("/subscriptions/:id", handler.DeleteSubscription)
+	routeUrl.GET("/subscriptions/:id/deliveries", handler.FindDeliveries)
+	routeUrl.POST("/deliveries/:id/replay", handler.ReplayDelivery)
+}
+
+func (w *WebhookHandler) FindSubscriptions(c echo.Context) error {
+	subscriptions, err := w.webhookUsecase.FindSubscriptions(c.Request().Context())
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch webhook subscriptions")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   subscriptions,
+	})
+}
+
+func (w *WebhookHandler) CreateSubscription(c echo.Context) error {
+	var body model.CreateWebhookSubscriptionInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	subscription, err := w.webhookUsecase.CreateSubscription(c.Request().Context(), body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, Response{
+		Status:  http.StatusCreated,
+		Message: "Webhook subscription created successfully",
+		Data:    subscription,
+	})
+}
+
+func (w *WebhookHandler) UpdateSubscription(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid webhook subscription ID format")
+	}
+
+	var body model.UpdateWebhookSubscriptionInput
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+
+	subscription, err := w.webhookUsecase.UpdateSubscription(c.Request().Context(), id, body)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Webhook subscription updated successfully",
+		Data:    subscription,
+	})
+}
+
+func (w *WebhookHandler) DeleteSubscription(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid webhook subscription ID format")
+	}
+
+	err = w.webhookUsecase.DeleteSubscription(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Webhook subscription not found")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status:  http.StatusOK,
+		Message: "Webhook subscription deleted successfully",
+	})
+}
+
+func (w *WebhookHandler) FindDeliveries(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid webhook subscription ID format")
+	}
+
+	var param model.WebhookDeliveryParam
+	err = echo.QueryParamsBinder(c).
+		Int64("limit", &param.Limit).
+		String("cursor", &param.Cursor).
+		String("status", &param.Status).
+		BindError()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid query parameters")
+	}
+
+	page, err := w.webhookUsecase.FindDeliveries(c.Request().Context(), id, param)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if errors.Is(err, model.ErrInvalidFilter) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch webhook deliveries")
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		Status: http.StatusOK,
+		Data:   page.Deliveries,
+		Meta:   page.Meta,
+	})
+}
+
+func (w *WebhookHandler) ReplayDelivery(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid webhook delivery ID format")
+	}
+
+	delivery, err := w.webhookUsecase.ReplayDelivery(c.Request().Context(), id)
+	if errors.Is(err, model.ErrForbidden) {
+		return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+	}
+	if errors.Is(err, model.ErrWebhookGone) {
+		return echo.NewHTTPError(http.StatusGone, "Webhook subscription no longer exists")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Webhook delivery not found")
+	}
+
+	return c.JSON(http.StatusAccepted, Response{
+		Status:  http.StatusAccepted,
+		Message: "Webhook delivery queued for replay",
+		Data:    delivery,
+	})
+}
@@ -0,0 +1,85 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"helpdesk-ticketing-system/internal/model"
+	"io"
+)
+
+// BlobReader reads a blob of known size as an io.ReadSeeker, so it can be
+// served with http.ServeContent. Each seek that moves the position opens a
+// new ranged read on the next Read, which keeps Range requests against
+// remote storage from downloading the whole blob.
+type BlobReader struct {
+	ctx     context.Context
+	storage model.IBlobStorage
+	key     string
+	size    int64
+	offset  int64
+	body    io.ReadCloser
+}
+
+func NewBlobReader(ctx context.Context, storage model.IBlobStorage, key string, size int64) *BlobReader {
+	return &BlobReader{
+		ctx:     ctx,
+		storage: storage,
+		key:     key,
+		size:    size,
+	}
+}
+
+func (b *BlobReader) Read(p []byte) (int, error) {
+	if b.offset >= b.size {
+		return 0, io.EOF
+	}
+
+	if b.body == nil {
+		body, err := b.storage.GetRange(b.ctx, b.key, b.offset, b.size-b.offset)
+		if err != nil {
+			return 0, err
+		}
+		b.body = body
+	}
+
+	n, err := b.body.Read(p)
+	b.offset += int64(n)
+
+	return n, err
+}
+
+func (b *BlobReader) Seek(offset int64, whence int) (int64, error) {
+	var position int64
+	switch whence {
+	case io.SeekStart:
+		position = offset
+	case io.SeekCurrent:
+		position = b.offset + offset
+	case io.SeekEnd:
+		position = b.size + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if position < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if position != b.offset {
+		b.Close()
+		b.offset = position
+	}
+
+	return position, nil
+}
+
+func (b *BlobReader) Close() error {
+	if b.body == nil {
+		return nil
+	}
+
+	err := b.body.Close()
+	b.body = nil
+
+	return err
+}
@@ -0,0 +1,154 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"helpdesk-ticketing-system/internal/model"
+)
+
+// rangeStorage serves one blob and records the ranges it was asked for.
+type rangeStorage struct {
+	content []byte
+	ranges  [][2]int64
+}
+
+func (s *rangeStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	return nil
+}
+
+func (s *rangeStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.content)), nil
+}
+
+func (s *rangeStorage) GetRange(ctx context.Context, key string, offset int64, length int64) (io.ReadCloser, error) {
+	s.ranges = append(s.ranges, [2]int64{offset, length})
+	return io.NopCloser(bytes.NewReader(s.content[offset : offset+length])), nil
+}
+
+func (s *rangeStorage) Stat(ctx context.Context, key string) (*model.BlobInfo, error) {
+	return &model.BlobInfo{Size: int64(len(s.content))}, nil
+}
+
+func (s *rangeStorage) Delete(ctx context.Context, key string) error {
+	return nil
+}
+
+func TestBlobReaderSeek(t *testing.T) {
+	content := []byte("0123456789")
+
+	tests := []struct {
+		name     string
+		offset   int64
+		whence   int
+		want     int64
+		wantErr  bool
+		wantRead string
+	}{
+		{name: "start", offset: 3, whence: io.SeekStart, want: 3, wantRead: "3456789"},
+		{name: "current", offset: 2, whence: io.SeekCurrent, want: 2, wantRead: "23456789"},
+		{name: "end", offset: -4, whence: io.SeekEnd, want: 6, wantRead: "6789"},
+		{name: "size", offset: 0, whence: io.SeekEnd, want: 10, wantRead: ""},
+		{name: "past the end", offset: 20, whence: io.SeekStart, want: 20, wantRead: ""},
+		{name: "negative", offset: -1, whence: io.SeekStart, wantErr: true},
+		{name: "invalid whence", offset: 0, whence: 7, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &rangeStorage{content: content}
+			reader := NewBlobReader(context.Background(), storage, "key", int64(len(content)))
+			defer reader.Close()
+
+			got, err := reader.Seek(tt.offset, tt.whence)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Seek(%d, %d) error = nil, want error", tt.offset, tt.whence)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("Seek(%d, %d) = %d, %v, want %d", tt.offset, tt.whence, got, err, tt.want)
+			}
+
+			read, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if string(read) != tt.wantRead {
+				t.Errorf("ReadAll() = %q, want %q", read, tt.wantRead)
+			}
+		})
+	}
+}
+
+func TestBlobReaderOpensOneRangePerSeek(t *testing.T) {
+	storage := &rangeStorage{content: []byte("0123456789")}
+	reader := NewBlobReader(context.Background(), storage, "key", 10)
+	defer reader.Close()
+
+	buf := make([]byte, 2)
+	io.ReadFull(reader, buf)
+	io.ReadFull(reader, buf)
+
+	// seeking to where the reader already is keeps the open range
+	reader.Seek(4, io.SeekStart)
+	io.ReadFull(reader, buf)
+
+	reader.Seek(8, io.SeekStart)
+	io.ReadFull(reader, buf)
+
+	want := [][2]int64{{0, 10}, {8, 2}}
+	if len(storage.ranges) != len(want) {
+		t.Fatalf("ranges = %v, want %v", storage.ranges, want)
+	}
+	for i := range want {
+		if storage.ranges[i] != want[i] {
+			t.Errorf("ranges = %v, want %v", storage.ranges, want)
+		}
+	}
+}
+
+func TestBlobReaderServeContentRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		rangeValue string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "whole", wantStatus: http.StatusOK, wantBody: "0123456789"},
+		{name: "prefix", rangeValue: "bytes=0-3", wantStatus: http.StatusPartialContent, wantBody: "0123"},
+		{name: "middle", rangeValue: "bytes=4-6", wantStatus: http.StatusPartialContent, wantBody: "456"},
+		{name: "suffix", rangeValue: "bytes=-2", wantStatus: http.StatusPartialContent, wantBody: "89"},
+		{name: "open ended", rangeValue: "bytes=7-", wantStatus: http.StatusPartialContent, wantBody: "789"},
+		{name: "unsatisfiable", rangeValue: "bytes=20-30", wantStatus: http.StatusRequestedRangeNotSatisfiable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &rangeStorage{content: []byte("0123456789")}
+			reader := NewBlobReader(context.Background(), storage, "key", 10)
+			defer reader.Close()
+
+			req := httptest.NewRequest(http.MethodGet, "/download", nil)
+			if tt.rangeValue != "" {
+				req.Header.Set("Range", tt.rangeValue)
+			}
+			rec := httptest.NewRecorder()
+
+			http.ServeContent(rec, req, "file.txt", time.Time{}, reader)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
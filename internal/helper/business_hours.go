@@ -0,0 +1,148 @@
+package helper
+
+import (
+	"sort"
+	"time"
+
+	"helpdesk-ticketing-system/internal/model"
+)
+
+// maxCalendarDays bounds the day-by-day walk so a calendar made only of
+// holidays cannot loop forever.
+const maxCalendarDays = 366 * 5
+
+type businessWindow struct {
+	start time.Time
+	end   time.Time
+}
+
+// AddBusinessDuration returns the moment d of business time after start.
+func AddBusinessDuration(start time.Time, d time.Duration, calendar *model.BusinessCalendar) time.Time {
+	if !hasBusinessHours(calendar) || d <= 0 {
+		return start.Add(d)
+	}
+
+	loc := calendarLocation(calendar)
+	cursor := start.In(loc)
+	remaining := d
+
+	for i := 0; i < maxCalendarDays; i++ {
+		for _, window := range businessWindows(cursor, calendar, loc) {
+			if !cursor.Before(window.end) {
+				continue
+			}
+
+			from := window.start
+			if cursor.After(from) {
+				from = cursor
+			}
+
+			available := window.end.Sub(from)
+			if remaining <= available {
+				return from.Add(remaining).In(start.Location())
+			}
+			remaining -= available
+		}
+
+		cursor = startOfDay(cursor).AddDate(0, 0, 1)
+	}
+
+	return start.Add(d)
+}
+
+// BusinessDuration returns how much business time lies between from and to.
+func BusinessDuration(from, to time.Time, calendar *model.BusinessCalendar) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+
+	if !hasBusinessHours(calendar) {
+		return to.Sub(from)
+	}
+
+	loc := calendarLocation(calendar)
+	cursor := from.In(loc)
+	end := to.In(loc)
+
+	var total time.Duration
+	for i := 0; i < maxCalendarDays && cursor.Before(end); i++ {
+		for _, window := range businessWindows(cursor, calendar, loc) {
+			windowStart := window.start
+			if cursor.After(windowStart) {
+				windowStart = cursor
+			}
+
+			windowEnd := window.end
+			if end.Before(windowEnd) {
+				windowEnd = end
+			}
+
+			if windowEnd.After(windowStart) {
+				total += windowEnd.Sub(windowStart)
+			}
+		}
+
+		cursor = startOfDay(cursor).AddDate(0, 0, 1)
+	}
+
+	return total
+}
+
+func hasBusinessHours(calendar *model.BusinessCalendar) bool {
+	return calendar != nil && len(calendar.Hours) > 0
+}
+
+func calendarLocation(calendar *model.BusinessCalendar) *time.Location {
+	loc, err := time.LoadLocation(calendar.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// businessWindows returns the working windows of the day containing t,
+// sorted by start time. Holidays have none.
+func businessWindows(t time.Time, calendar *model.BusinessCalendar, loc *time.Location) []businessWindow {
+	day := startOfDay(t)
+
+	for _, holiday := range calendar.Holidays {
+		year, month, date := holiday.Date.Date()
+		if year == day.Year() && month == day.Month() && date == day.Day() {
+			return nil
+		}
+	}
+
+	var windows []businessWindow
+	for _, hour := range calendar.Hours {
+		if time.Weekday(hour.Weekday) != day.Weekday() {
+			continue
+		}
+
+		start, err := time.ParseInLocation("15:04", hour.StartTime, loc)
+		if err != nil {
+			continue
+		}
+
+		end, err := time.ParseInLocation("15:04", hour.EndTime, loc)
+		if err != nil || !end.After(start) {
+			continue
+		}
+
+		windows = append(windows, businessWindow{
+			start: time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, loc),
+			end:   time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, loc),
+		})
+	}
+
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].start.Before(windows[j].start)
+	})
+
+	return windows
+}
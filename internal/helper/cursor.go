@@ -0,0 +1,46 @@
+package helper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+type cursor struct {
+	SortBy string `json:"s,omitempty"`
+	Value  string `json:"v"`
+	ID     int64  `json:"id"`
+}
+
+// EncodeCursor packs the sort value and ID of the last row of a page into an
+// opaque token for keyset pagination.
+func EncodeCursor(value string, id int64) string {
+	return EncodeSortCursor("", value, id)
+}
+
+func DecodeCursor(token string) (value string, id int64, err error) {
+	_, value, id, err = DecodeSortCursor(token)
+	return value, id, err
+}
+
+// EncodeSortCursor is EncodeCursor for lists with a choice of sort column.
+// The column is kept in the token so a cursor cannot be replayed against a
+// different sort.
+func EncodeSortCursor(sortBy string, value string, id int64) string {
+	data, _ := json.Marshal(cursor{SortBy: sortBy, Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeSortCursor(token string) (sortBy string, value string, id int64, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", "", 0, errors.New("invalid cursor")
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == 0 {
+		return "", "", 0, errors.New("invalid cursor")
+	}
+
+	return c.SortBy, c.Value, c.ID, nil
+}
@@ -0,0 +1,73 @@
+package helper
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestSortCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		sortBy string
+		value  string
+		id     int64
+	}{
+		{name: "default sort", value: "2025-06-01T10:00:00Z", id: 42},
+		{name: "priority sort", sortBy: "priority", value: "high", id: 7},
+		{name: "empty value", sortBy: "due_by", value: "", id: 1},
+		{name: "value with separators", sortBy: "title", value: `a,b "c" /d`, id: 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := EncodeSortCursor(tt.sortBy, tt.value, tt.id)
+
+			sortBy, value, id, err := DecodeSortCursor(token)
+			if err != nil {
+				t.Fatalf("DecodeSortCursor(%q) error = %v", token, err)
+			}
+			if sortBy != tt.sortBy || value != tt.value || id != tt.id {
+				t.Errorf("DecodeSortCursor(%q) = %q, %q, %d, want %q, %q, %d", token, sortBy, value, id, tt.sortBy, tt.value, tt.id)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	token := EncodeCursor("2025-06-01T10:00:00Z", 42)
+
+	value, id, err := DecodeCursor(token)
+	if err != nil {
+		t.Fatalf("DecodeCursor(%q) error = %v", token, err)
+	}
+	if value != "2025-06-01T10:00:00Z" || id != 42 {
+		t.Errorf("DecodeCursor(%q) = %q, %d", token, value, id)
+	}
+
+	sortBy, _, _, err := DecodeSortCursor(token)
+	if err != nil || sortBy != "" {
+		t.Errorf("DecodeSortCursor(%q) sort = %q, error = %v, want no sort", token, sortBy, err)
+	}
+}
+
+func TestDecodeSortCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "not base64", token: "!!!"},
+		{name: "not json", token: base64.RawURLEncoding.EncodeToString([]byte("nope"))},
+		{name: "missing id", token: base64.RawURLEncoding.EncodeToString([]byte(`{"v":"x"}`))},
+		{name: "padded base64", token: base64.URLEncoding.EncodeToString([]byte(`{"v":"x","id":1}`))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := DecodeSortCursor(tt.token)
+			if err == nil {
+				t.Errorf("DecodeSortCursor(%q) error = nil, want error", tt.token)
+			}
+		})
+	}
+}
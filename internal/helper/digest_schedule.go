@@ -0,0 +1,40 @@
+package helper
+
+import (
+	"helpdesk-ticketing-system/internal/model"
+	"time"
+)
+
+// NextDigestAt returns when the next digest of the user goes out, or nil when
+// they have digests turned off. A digest due during quiet hours waits for
+// them to end.
+func NextDigestAt(now time.Time, settings *model.NotificationSettings) *time.Time {
+	if settings == nil {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+
+	var next time.Time
+	switch settings.DigestMode {
+	case model.NotificationDigestHourly:
+		next = time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, loc).Add(time.Hour)
+	case model.NotificationDigestDaily:
+		next = time.Date(local.Year(), local.Month(), local.Day(), settings.DigestHour, 0, 0, 0, loc)
+		if !next.After(local) {
+			next = next.AddDate(0, 0, 1)
+		}
+	default:
+		return nil
+	}
+
+	if end := QuietHoursEnd(next, settings); end != nil {
+		return end
+	}
+
+	return &next
+}
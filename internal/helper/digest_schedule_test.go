@@ -0,0 +1,91 @@
+package helper
+
+import (
+	"helpdesk-ticketing-system/internal/model"
+	"testing"
+	"time"
+)
+
+func TestNextDigestAt(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skip("time zone database not available:", err)
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		settings *model.NotificationSettings
+		want     *time.Time
+	}{
+		{
+			name: "no settings",
+			now:  time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "digests off",
+			now:      time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+			settings: &model.NotificationSettings{Timezone: "UTC", DigestMode: model.NotificationDigestOff},
+		},
+		{
+			name:     "hourly",
+			now:      time.Date(2025, 6, 1, 10, 20, 0, 0, time.UTC),
+			settings: &model.NotificationSettings{Timezone: "UTC", DigestMode: model.NotificationDigestHourly},
+			want:     timePtr(time.Date(2025, 6, 1, 11, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:     "hourly on the hour",
+			now:      time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+			settings: &model.NotificationSettings{Timezone: "UTC", DigestMode: model.NotificationDigestHourly},
+			want:     timePtr(time.Date(2025, 6, 1, 11, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:     "daily later today",
+			now:      time.Date(2025, 6, 1, 6, 0, 0, 0, time.UTC),
+			settings: &model.NotificationSettings{Timezone: "UTC", DigestMode: model.NotificationDigestDaily, DigestHour: 8},
+			want:     timePtr(time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:     "daily at the hour goes to tomorrow",
+			now:      time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC),
+			settings: &model.NotificationSettings{Timezone: "UTC", DigestMode: model.NotificationDigestDaily, DigestHour: 8},
+			want:     timePtr(time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:     "daily in user time zone",
+			now:      time.Date(2025, 6, 1, 2, 0, 0, 0, time.UTC), // 09:00 in Jakarta
+			settings: &model.NotificationSettings{Timezone: "Asia/Jakarta", DigestMode: model.NotificationDigestDaily, DigestHour: 8},
+			want:     timePtr(time.Date(2025, 6, 2, 8, 0, 0, 0, jakarta)),
+		},
+		{
+			name: "daily during quiet hours waits for them to end",
+			now:  time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+			settings: &model.NotificationSettings{
+				Timezone:        "UTC",
+				DigestMode:      model.NotificationDigestDaily,
+				DigestHour:      6,
+				QuietHoursStart: "22:00",
+				QuietHoursEnd:   "07:00",
+			},
+			want: timePtr(time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "hourly during quiet hours waits for them to end",
+			now:  time.Date(2025, 6, 1, 22, 30, 0, 0, time.UTC),
+			settings: &model.NotificationSettings{
+				Timezone:        "UTC",
+				DigestMode:      model.NotificationDigestHourly,
+				QuietHoursStart: "22:00",
+				QuietHoursEnd:   "07:00",
+			},
+			want: timePtr(time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextDigestAt(tt.now, tt.settings)
+			assertTimePtr(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package helper
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"helpdesk-ticketing-system/internal/config"
+)
+
+// SignAttachmentDownload returns the signature of a download link for the
+// attachment that stops working at expires, a Unix time.
+func SignAttachmentDownload(id int64, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(config.JWTSigningKey()))
+	fmt.Fprintf(mac, "attachment-download:%d:%d", id, expires)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func VerifyAttachmentDownload(id int64, expires int64, signature string) bool {
+	expected := SignAttachmentDownload(id, expires)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
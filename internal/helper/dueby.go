@@ -2,25 +2,33 @@ package helper
 
 import (
 	"fmt"
+	"helpdesk-ticketing-system/internal/model"
 	"strings"
 	"time"
 )
 
-func CalculateDueBy(priority string) *time.Time {
-	var duration time.Duration
+// DefaultSLATargets returns the first-response and resolution targets used
+// when no SLA policy covers the ticket priority.
+func DefaultSLATargets(priority string) (firstResponse time.Duration, resolution time.Duration) {
 	switch strings.ToLower(priority) {
 	case "high":
-		duration = 60 * time.Minute
+		resolution = 60 * time.Minute
 	case "medium":
-		duration = 90 * time.Minute
+		resolution = 90 * time.Minute
 	case "low":
-		duration = 120 * time.Minute
+		resolution = 120 * time.Minute
 	case "very_low":
-		duration = 240 * time.Minute
+		resolution = 240 * time.Minute
 	default:
-		duration = 90 * time.Minute
+		resolution = 90 * time.Minute
 	}
-	due := time.Now().Add(duration)
+	return resolution / 2, resolution
+}
+
+// CalculateDueBy adds target to start counting only business time of the
+// calendar. A nil calendar means the clock runs around the clock.
+func CalculateDueBy(start time.Time, target time.Duration, calendar *model.BusinessCalendar) *time.Time {
+	due := AddBusinessDuration(start, target, calendar)
 	return &due
 }
 
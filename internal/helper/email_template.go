@@ -0,0 +1,107 @@
+package helper
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/model"
+)
+
+// EmailTemplates holds the templates of every notification event. Each event
+// has three files in the template directory: <event>.subject.tmpl and
+// <event>.txt.tmpl for text/template, and <event>.html.tmpl for html/template.
+type EmailTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+var emailTemplateFuncs = map[string]interface{}{
+	"upper": strings.ToUpper,
+	"title": titleCase,
+	"date": func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format("Mon, 02 Jan 2006 15:04 MST")
+	},
+}
+
+// titleCase turns a value such as "in_progress" into "In progress".
+func titleCase(s string) string {
+	s = strings.ReplaceAll(s, "_", " ")
+	first, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+
+	return string(unicode.ToUpper(first)) + s[size:]
+}
+
+func LoadEmailTemplates(dir string) (*EmailTemplates, error) {
+	text, err := texttemplate.New("email").
+		Funcs(emailTemplateFuncs).
+		ParseGlob(filepath.Join(dir, "*.txt.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load text email templates: %w", err)
+	}
+
+	text, err = text.ParseGlob(filepath.Join(dir, "*.subject.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load email subject templates: %w", err)
+	}
+
+	html, err := htmltemplate.New("email").
+		Funcs(emailTemplateFuncs).
+		ParseGlob(filepath.Join(dir, "*.html.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load html email templates: %w", err)
+	}
+
+	return &EmailTemplates{text: text, html: html}, nil
+}
+
+// Render renders the templates of the notification event. Notifications
+// without an event use the generic templates.
+func (e *EmailTemplates) Render(notification *model.Notification) (*model.RenderedEmail, error) {
+	event := notification.Event
+	if event == "" {
+		event = model.NotificationEventGeneric
+	}
+
+	var subject, text, html bytes.Buffer
+
+	err := e.text.ExecuteTemplate(&subject, event+".subject.tmpl", notification)
+	if err != nil {
+		return nil, err
+	}
+
+	err = e.text.ExecuteTemplate(&text, event+".txt.tmpl", notification)
+	if err != nil {
+		return nil, err
+	}
+
+	err = e.html.ExecuteTemplate(&html, event+".html.tmpl", notification)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.RenderedEmail{
+		// a subject must stay on one header line
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// TicketURL links to the ticket in the agent UI.
+func TicketURL(ticketID int64) string {
+	return fmt.Sprintf("%s/tickets/%d", strings.TrimSuffix(config.AppBaseURL(), "/"), ticketID)
+}
@@ -0,0 +1,26 @@
+package helper
+
+import "testing"
+
+func TestTitleCase(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "empty", s: "", want: ""},
+		{name: "status", s: "in_progress", want: "In progress"},
+		{name: "already capitalized", s: "High", want: "High"},
+		{name: "multibyte first letter", s: "élevée", want: "Élevée"},
+		{name: "non letter", s: "24h_queue", want: "24h queue"},
+		{name: "invalid utf-8 is kept", s: "\xffabc", want: "�abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titleCase(tt.s); got != tt.want {
+				t.Errorf("titleCase(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
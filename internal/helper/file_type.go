@@ -0,0 +1,92 @@
+package helper
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// sniffLength is the most content http.DetectContentType looks at.
+const sniffLength = 512
+
+// executableSignatures adds the program formats that http.DetectContentType
+// reports as application/octet-stream.
+var executableSignatures = []struct {
+	prefix    []byte
+	mediaType string
+}{
+	{[]byte("MZ"), "application/x-msdownload"},
+	{[]byte("\x7fELF"), "application/x-executable"},
+	{[]byte("\xcf\xfa\xed\xfe"), "application/x-mach-binary"},
+	{[]byte("\xce\xfa\xed\xfe"), "application/x-mach-binary"},
+	{[]byte("#!"), "text/x-shellscript"},
+}
+
+// SniffContentType detects the media type of r from its first bytes, without
+// parameters such as the charset. The returned reader yields the whole
+// content, including the bytes that were sniffed.
+func SniffContentType(r io.Reader) (string, io.Reader, error) {
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	head = head[:n]
+
+	return DetectContentType(head), io.MultiReader(bytes.NewReader(head), r), nil
+}
+
+func DetectContentType(head []byte) string {
+	for _, signature := range executableSignatures {
+		if bytes.HasPrefix(head, signature.prefix) {
+			return signature.mediaType
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream"
+	}
+
+	return mediaType
+}
+
+// FileTypeAllowed checks a file against allow and deny lists of media types,
+// such as "application/pdf" or "image/*", and extensions such as ".exe". A
+// file is denied when its type or extension is on the deny list, and must
+// match the allow list by either one unless that list is empty.
+func FileTypeAllowed(mediaType string, filename string, allowed []string, denied []string) bool {
+	if matchFileType(mediaType, filename, denied) {
+		return false
+	}
+
+	return len(allowed) == 0 || matchFileType(mediaType, filename, allowed)
+}
+
+func matchFileType(mediaType string, filename string, patterns []string) bool {
+	mediaType = strings.ToLower(mediaType)
+	extension := strings.ToLower(filepath.Ext(filename))
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		switch {
+		case pattern == "":
+			continue
+		case strings.HasPrefix(pattern, "."):
+			if extension == pattern {
+				return true
+			}
+		case strings.HasSuffix(pattern, "/*"):
+			if strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		case mediaType == pattern:
+			return true
+		}
+	}
+
+	return false
+}
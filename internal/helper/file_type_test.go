@@ -0,0 +1,82 @@
+package helper
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		head string
+		want string
+	}{
+		{name: "pdf", head: "%PDF-1.7\n", want: "application/pdf"},
+		{name: "png", head: "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR", want: "image/png"},
+		{name: "plain text drops charset", head: "hello world", want: "text/plain"},
+		{name: "html", head: "<!DOCTYPE html><html>", want: "text/html"},
+		{name: "windows executable", head: "MZ\x90\x00\x03", want: "application/x-msdownload"},
+		{name: "elf", head: "\x7fELF\x02\x01", want: "application/x-executable"},
+		{name: "mach-o", head: "\xcf\xfa\xed\xfe\x07", want: "application/x-mach-binary"},
+		{name: "shell script", head: "#!/bin/sh\nrm -rf /\n", want: "text/x-shellscript"},
+		{name: "unknown binary", head: "\x00\x01\x02\x03", want: "application/octet-stream"},
+		{name: "empty", head: "", want: "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectContentType([]byte(tt.head)); got != tt.want {
+				t.Errorf("DetectContentType(%q) = %q, want %q", tt.head, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSniffContentTypeKeepsContent(t *testing.T) {
+	content := "%PDF-1.7\n" + strings.Repeat("x", 1000)
+
+	mediaType, r, err := SniffContentType(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("SniffContentType() error = %v", err)
+	}
+	if mediaType != "application/pdf" {
+		t.Errorf("SniffContentType() type = %q, want application/pdf", mediaType)
+	}
+
+	read, err := io.ReadAll(r)
+	if err != nil || string(read) != content {
+		t.Errorf("SniffContentType() reader returned %d bytes, %v, want the %d bytes of content", len(read), err, len(content))
+	}
+}
+
+func TestFileTypeAllowed(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		filename  string
+		allowed   []string
+		denied    []string
+		want      bool
+	}{
+		{name: "no lists", mediaType: "application/pdf", filename: "a.pdf", want: true},
+		{name: "allowed type", mediaType: "application/pdf", filename: "a.pdf", allowed: []string{"application/pdf"}, want: true},
+		{name: "allowed wildcard", mediaType: "image/png", filename: "a.png", allowed: []string{"image/*"}, want: true},
+		{name: "allowed extension", mediaType: "text/plain", filename: "app.LOG", allowed: []string{".log"}, want: true},
+		{name: "not allowed", mediaType: "application/zip", filename: "a.zip", allowed: []string{"image/*", ".pdf"}},
+		{name: "denied type", mediaType: "application/x-msdownload", filename: "setup.txt", denied: []string{"application/x-msdownload"}},
+		{name: "denied extension", mediaType: "text/plain", filename: "run.EXE", denied: []string{".exe"}},
+		{name: "deny wins over allow", mediaType: "image/svg+xml", filename: "a.svg", allowed: []string{"image/*"}, denied: []string{"image/svg+xml"}},
+		{name: "case and spaces in patterns", mediaType: "Image/PNG", filename: "a.png", allowed: []string{" IMAGE/png "}, want: true},
+		{name: "blank patterns are ignored", mediaType: "application/zip", filename: "a.zip", allowed: []string{""}, denied: []string{""}},
+		{name: "wildcard needs the slash", mediaType: "imagefoo/png", filename: "a.png", allowed: []string{"image/*"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FileTypeAllowed(tt.mediaType, tt.filename, tt.allowed, tt.denied); got != tt.want {
+				t.Errorf("FileTypeAllowed(%q, %q) = %v, want %v", tt.mediaType, tt.filename, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package helper
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -21,18 +24,42 @@ func CheckPasswordHash(password, hash string) bool {
 	return err == nil
 }
 
-func GenerateToken(userID int64) (strToken string, err error) {
+func GenerateToken(userID int64, role string) (strToken string, err error) {
 	expiredAt := time.Now().UTC().Add(config.JWTExp())
+	jti, err := RandomToken(16)
+	if err != nil {
+		return
+	}
+
 	strToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"exp":     expiredAt.Unix(),
+		"jti":     jti,
 		"user_id": userID,
+		"role":    role,
 	}).SignedString([]byte(config.JWTSigningKey()))
 	return
 }
 
+// HashToken returns the hex SHA-256 of a token, used wherever a token is
+// stored or used as a key instead of the raw value.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RandomToken returns n random bytes encoded as hex.
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
 func DecodeToken(token string, claim *model.CustomClaims) (err error) {
-	jwt.ParseWithClaims(token, claim, func(t *jwt.Token) (interface{}, error) {
+	_, err = jwt.ParseWithClaims(token, claim, func(t *jwt.Token) (interface{}, error) {
 		return []byte(config.JWTSigningKey()), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	return
 }
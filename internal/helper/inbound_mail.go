@@ -0,0 +1,299 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"helpdesk-ticketing-system/internal/model"
+)
+
+var (
+	messageIDPattern      = regexp.MustCompile(`<([^<>\s]+)>`)
+	subjectTokenPattern   = regexp.MustCompile(`\[#(\d+)\]`)
+	htmlBreakPattern      = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>|</tr>`)
+	htmlTagPattern        = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlSkipPattern       = regexp.MustCompile(`(?is)<(style|script|head)[^>]*>.*?</(style|script|head)>`)
+	quoteHeaderPattern    = regexp.MustCompile(`^On .+wrote:\s*$`)
+	blankLinesPattern     = regexp.MustCompile(`\n{3,}`)
+	mailHeaderDecoder     = &mime.WordDecoder{CharsetReader: charsetReader}
+	errUnsupportedCharset = errors.New("unsupported charset")
+)
+
+// ParseInboundEmail reads a raw RFC 5322 message. The first text/plain and
+// text/html parts become the body and every other part with a file name, or
+// any part marked as an attachment, becomes an attachment.
+func ParseInboundEmail(r io.Reader) (*model.InboundEmail, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+
+	parser := mail.AddressParser{WordDecoder: mailHeaderDecoder}
+	from, err := parser.Parse(msg.Header.Get("From"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid From header: %w", err)
+	}
+
+	subject, err := mailHeaderDecoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	email := &model.InboundEmail{
+		MessageID:     firstMessageID(msg.Header.Get("Message-ID")),
+		InReplyTo:     firstMessageID(msg.Header.Get("In-Reply-To")),
+		References:    messageIDs(msg.Header.Get("References")),
+		From:          strings.ToLower(from.Address),
+		FromName:      from.Name,
+		Subject:       strings.TrimSpace(subject),
+		AutoSubmitted: isAutoSubmitted(msg.Header),
+
+		AuthenticationResults: msg.Header["Authentication-Results"],
+	}
+
+	err = readMailPart(email, msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Header.Get("Content-Disposition"), msg.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if email.Text == "" && email.HTML != "" {
+		email.Text = HTMLToText(email.HTML)
+	}
+
+	return email, nil
+}
+
+func readMailPart(email *model.InboundEmail, contentType string, encoding string, disposition string, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read multipart body: %w", err)
+			}
+
+			err = readMailPart(email, part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part.Header.Get("Content-Disposition"), part)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := io.ReadAll(decodeTransfer(encoding, body))
+	if err != nil {
+		return fmt.Errorf("failed to decode %s part: %w", mediaType, err)
+	}
+
+	dispositionType, dispositionParams, _ := mime.ParseMediaType(disposition)
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if decoded, err := mailHeaderDecoder.DecodeHeader(filename); err == nil {
+		filename = decoded
+	}
+
+	isBody := dispositionType != "attachment" && filename == ""
+	switch {
+	case isBody && mediaType == "text/plain" && email.Text == "":
+		email.Text = strings.TrimSpace(decodeCharset(params["charset"], content))
+	case isBody && mediaType == "text/html" && email.HTML == "":
+		email.HTML = decodeCharset(params["charset"], content)
+	case isBody && strings.HasPrefix(mediaType, "text/"):
+		// alternative bodies beyond the first are dropped
+	default:
+		if filename == "" {
+			filename = "attachment"
+		}
+		email.Attachments = append(email.Attachments, model.InboundAttachment{
+			Filename:    filename,
+			ContentType: mediaType,
+			Content:     content,
+		})
+	}
+
+	return nil
+}
+
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// decodeCharset converts Latin-1 text to UTF-8. Other charsets are passed
+// through unchanged.
+func decodeCharset(charset string, content []byte) string {
+	reader, err := charsetReader(charset, bytes.NewReader(content))
+	if err != nil {
+		return string(content)
+	}
+
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return string(content)
+	}
+
+	return string(decoded)
+}
+
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "us-ascii":
+		return input, nil
+	case "iso-8859-1", "latin1", "windows-1252":
+		content, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+
+		runes := make([]rune, len(content))
+		for i, b := range content {
+			runes[i] = rune(b)
+		}
+		return strings.NewReader(string(runes)), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedCharset, charset)
+	}
+}
+
+func isAutoSubmitted(header mail.Header) bool {
+	autoSubmitted := strings.ToLower(header.Get("Auto-Submitted"))
+	if autoSubmitted != "" && autoSubmitted != "no" {
+		return true
+	}
+
+	switch strings.ToLower(header.Get("Precedence")) {
+	case "bulk", "junk", "list", "auto_reply":
+		return true
+	}
+
+	return header.Get("X-Autoreply") != "" || header.Get("X-Autorespond") != ""
+}
+
+// SenderAuthenticated reports whether an Authentication-Results header added
+// by authservID records a DMARC pass for the domain of the From address.
+// Headers from any other server are ignored, since a sender can write them
+// too; the receiving MTA must strip incoming ones with its own authserv-id.
+func SenderAuthenticated(email *model.InboundEmail, authservID string) bool {
+	if authservID == "" {
+		return false
+	}
+
+	_, domain, ok := strings.Cut(email.From, "@")
+	if !ok {
+		return false
+	}
+
+	for _, header := range email.AuthenticationResults {
+		results := strings.Split(header, ";")
+
+		// the authserv-id may be followed by a version number
+		id := strings.Fields(results[0])
+		if len(id) == 0 || !strings.EqualFold(id[0], authservID) {
+			continue
+		}
+
+		for _, result := range results[1:] {
+			fields := strings.Fields(result)
+			if len(fields) == 0 || !strings.EqualFold(fields[0], "dmarc=pass") {
+				continue
+			}
+
+			for _, property := range fields[1:] {
+				if from, ok := strings.CutPrefix(strings.ToLower(property), "header.from="); ok && from == domain {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func firstMessageID(value string) string {
+	ids := messageIDs(value)
+	if len(ids) == 0 {
+		return ""
+	}
+
+	return ids[0]
+}
+
+func messageIDs(value string) []string {
+	var ids []string
+	for _, match := range messageIDPattern.FindAllStringSubmatch(value, -1) {
+		ids = append(ids, match[1])
+	}
+
+	return ids
+}
+
+// TicketIDFromSubject returns the ticket of a "[#123]" token in the subject,
+// or 0 when there is none.
+func TicketIDFromSubject(subject string) int64 {
+	match := subjectTokenPattern.FindStringSubmatch(subject)
+	if match == nil {
+		return 0
+	}
+
+	id, _ := strconv.ParseInt(match[1], 10, 64)
+	return id
+}
+
+// HTMLToText keeps the text of an HTML body with line breaks where block
+// elements ended.
+func HTMLToText(body string) string {
+	body = htmlSkipPattern.ReplaceAllString(body, "")
+	body = htmlBreakPattern.ReplaceAllString(body, "\n")
+	body = htmlTagPattern.ReplaceAllString(body, "")
+	body = html.UnescapeString(body)
+
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// StripQuotedReply cuts a reply at the quoted message it answers, so only the
+// new text is kept as a comment.
+func StripQuotedReply(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if quoteHeaderPattern.MatchString(trimmed) ||
+			strings.HasPrefix(trimmed, "-----Original Message-----") ||
+			strings.HasPrefix(trimmed, ">") {
+			lines = lines[:i]
+			break
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,227 @@
+package helper
+
+import (
+	"helpdesk-ticketing-system/internal/model"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStripQuotedReply(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "no quote", text: "Thanks, that fixed it.", want: "Thanks, that fixed it."},
+		{
+			name: "gmail style",
+			text: "Still broken.\n\nOn Mon, Jun 2, 2025 at 10:00 AM Support <support@example.com> wrote:\n> Please try again.",
+			want: "Still broken.",
+		},
+		{
+			name: "outlook style",
+			text: "See attached.\r\n\r\n-----Original Message-----\r\nFrom: Support",
+			want: "See attached.",
+		},
+		{
+			name: "quoted lines only",
+			text: "Works now\n> did you restart it?\n> ok",
+			want: "Works now",
+		},
+		{
+			name: "indented quote header",
+			text: "Yes\n   On Tue, Support wrote:   \nold text",
+			want: "Yes",
+		},
+		{name: "only a quote", text: "> earlier message", want: ""},
+		{
+			name: "wrote without on prefix is kept",
+			text: "She wrote: the server is down\nsince 9am",
+			want: "She wrote: the server is down\nsince 9am",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripQuotedReply(tt.text); got != tt.want {
+				t.Errorf("StripQuotedReply(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInboundEmail(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want *model.InboundEmail
+	}{
+		{
+			name: "plain text",
+			raw: "From: Jane Doe <Jane@Example.com>\r\n" +
+				"Subject: Printer on fire\r\n" +
+				"Message-ID: <abc@example.com>\r\n" +
+				"\r\n" +
+				"It is on fire.\r\n",
+			want: &model.InboundEmail{
+				MessageID: "abc@example.com",
+				From:      "jane@example.com",
+				FromName:  "Jane Doe",
+				Subject:   "Printer on fire",
+				Text:      "It is on fire.",
+			},
+		},
+		{
+			name: "reply with encoded subject and references",
+			raw: "From: jane@example.com\r\n" +
+				"Subject: =?UTF-8?Q?Re:_[#42]_Caf=C3=A9?=\r\n" +
+				"Message-ID: <reply@example.com>\r\n" +
+				"In-Reply-To: <ticket-42@helpdesk>\r\n" +
+				"References: <ticket-42@helpdesk> <c1@helpdesk>\r\n" +
+				"Auto-Submitted: auto-replied\r\n" +
+				"Authentication-Results: mx.example.net; dmarc=pass header.from=example.com\r\n" +
+				"\r\n" +
+				"Out of office\r\n",
+			want: &model.InboundEmail{
+				MessageID:             "reply@example.com",
+				InReplyTo:             "ticket-42@helpdesk",
+				References:            []string{"ticket-42@helpdesk", "c1@helpdesk"},
+				From:                  "jane@example.com",
+				Subject:               "Re: [#42] Café",
+				Text:                  "Out of office",
+				AutoSubmitted:         true,
+				AuthenticationResults: []string{"mx.example.net; dmarc=pass header.from=example.com"},
+			},
+		},
+		{
+			name: "multipart with html body and attachment",
+			raw: "From: jane@example.com\r\n" +
+				"Subject: Logs\r\n" +
+				"Content-Type: multipart/mixed; boundary=outer\r\n" +
+				"\r\n" +
+				"--outer\r\n" +
+				"Content-Type: text/html; charset=iso-8859-1\r\n" +
+				"Content-Transfer-Encoding: quoted-printable\r\n" +
+				"\r\n" +
+				"<p>Caf=E9 log</p><p>attached</p>\r\n" +
+				"--outer\r\n" +
+				"Content-Type: text/plain; name=\"app.log\"\r\n" +
+				"Content-Disposition: attachment; filename=\"app.log\"\r\n" +
+				"Content-Transfer-Encoding: base64\r\n" +
+				"\r\n" +
+				"ZXJyb3IK\r\n" +
+				"--outer--\r\n",
+			want: &model.InboundEmail{
+				From:    "jane@example.com",
+				Subject: "Logs",
+				Text:    "Café log\nattached",
+				HTML:    "<p>Café log</p><p>attached</p>",
+				Attachments: []model.InboundAttachment{
+					{Filename: "app.log", ContentType: "text/plain", Content: []byte("error\n")},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInboundEmail(strings.NewReader(tt.raw))
+			if err != nil {
+				t.Fatalf("ParseInboundEmail() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseInboundEmail() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInboundEmailInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{name: "no header", raw: ""},
+		{name: "no from", raw: "Subject: hi\r\n\r\nbody"},
+		{name: "bad from", raw: "From: not an address\r\n\r\nbody"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseInboundEmail(strings.NewReader(tt.raw))
+			if err == nil {
+				t.Error("ParseInboundEmail() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestSenderAuthenticated(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       string
+		results    []string
+		authservID string
+		want       bool
+	}{
+		{
+			name:       "dmarc pass from our server",
+			from:       "jane@example.com",
+			results:    []string{"mx.example.net; spf=pass smtp.mailfrom=example.com; dmarc=pass header.from=example.com"},
+			authservID: "mx.example.net",
+			want:       true,
+		},
+		{
+			name:       "authserv-id with version",
+			from:       "jane@example.com",
+			results:    []string{"MX.example.net 1; dmarc=pass (p=reject) header.from=Example.com"},
+			authservID: "mx.example.net",
+			want:       true,
+		},
+		{
+			name:       "no authserv-id configured",
+			from:       "jane@example.com",
+			results:    []string{"mx.example.net; dmarc=pass header.from=example.com"},
+			authservID: "",
+		},
+		{
+			name:       "header from another server",
+			from:       "jane@example.com",
+			results:    []string{"evil.example.org; dmarc=pass header.from=example.com"},
+			authservID: "mx.example.net",
+		},
+		{
+			name:       "dmarc fail",
+			from:       "jane@example.com",
+			results:    []string{"mx.example.net; dmarc=fail header.from=example.com"},
+			authservID: "mx.example.net",
+		},
+		{
+			name:       "pass for another domain",
+			from:       "jane@example.com",
+			results:    []string{"mx.example.net; dmarc=pass header.from=other.com"},
+			authservID: "mx.example.net",
+		},
+		{
+			name:       "spf pass only",
+			from:       "jane@example.com",
+			results:    []string{"mx.example.net; spf=pass smtp.mailfrom=example.com"},
+			authservID: "mx.example.net",
+		},
+		{
+			name:       "no header",
+			from:       "jane@example.com",
+			authservID: "mx.example.net",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := &model.InboundEmail{From: tt.from, AuthenticationResults: tt.results}
+			if got := SenderAuthenticated(email, tt.authservID); got != tt.want {
+				t.Errorf("SenderAuthenticated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
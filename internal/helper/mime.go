@@ -0,0 +1,97 @@
+package helper
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/model"
+)
+
+var notificationMessageIDPattern = regexp.MustCompile(`^ticket-(\d+)\.notification-\d+@`)
+
+// NotificationMessageID returns the Message-ID of a notification email, which
+// carries the ticket so replies can be threaded without a lookup.
+func NotificationMessageID(notification *model.Notification) string {
+	host := "localhost"
+	if baseURL, err := url.Parse(config.AppBaseURL()); err == nil && baseURL.Hostname() != "" {
+		host = baseURL.Hostname()
+	}
+
+	return fmt.Sprintf("ticket-%d.notification-%d@%s", notification.TicketID, notification.ID, host)
+}
+
+// TicketIDFromMessageID returns the ticket of a Message-ID made by
+// NotificationMessageID, or 0 for any other.
+func TicketIDFromMessageID(messageID string) int64 {
+	match := notificationMessageIDPattern.FindStringSubmatch(messageID)
+	if match == nil {
+		return 0
+	}
+
+	id, _ := strconv.ParseInt(match[1], 10, 64)
+	return id
+}
+
+// BuildMultipartEmail builds a multipart/alternative message with the plain
+// text part first, so clients that cannot show HTML fall back to it.
+func BuildMultipartEmail(from string, to string, email *model.RenderedEmail) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=\"utf-8\"", email.Text},
+		{"text/html; charset=\"utf-8\"", email.HTML},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := quotedprintable.NewWriter(partWriter)
+		_, err = encoder.Write([]byte(part.content))
+		if err != nil {
+			return nil, err
+		}
+
+		err = encoder.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	if email.MessageID != "" {
+		fmt.Fprintf(&msg, "Message-ID: <%s>\r\n", email.MessageID)
+	}
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%q\r\n", writer.Boundary())
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
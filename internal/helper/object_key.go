@@ -0,0 +1,58 @@
+package helper
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const maxObjectFilenameLength = 100
+
+var unsafeFilenamePattern = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// AttachmentObjectKey returns a new blob key for a file on the ticket. The
+// random prefix keeps uploads with the same name apart, and the name is
+// reduced to characters that are safe in paths and URLs.
+func AttachmentObjectKey(ticketID int64, filename string) (string, error) {
+	prefix, err := RandomToken(8)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("tickets/%d/%s-%s", ticketID, prefix, SafeFilename(filename)), nil
+}
+
+// SafeFilename drops any directory part of the name and replaces runs of
+// other characters than letters, digits, dots, dashes and underscores.
+func SafeFilename(filename string) string {
+	name := filepath.Base(strings.ReplaceAll(filename, "\\", "/"))
+	name = strings.Trim(unsafeFilenamePattern.ReplaceAllString(name, "_"), "._")
+	if name == "" {
+		name = "file"
+	}
+
+	if len(name) > maxObjectFilenameLength {
+		name = name[len(name)-maxObjectFilenameLength:]
+	}
+
+	return name
+}
+
+// CleanFilename returns the name to show for an uploaded file: the client's
+// name without any directory part or control characters.
+func CleanFilename(filename string) string {
+	name := filepath.Base(strings.ReplaceAll(filename, "\\", "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" || name == "." || name == "/" {
+		name = "file"
+	}
+
+	return name
+}
@@ -0,0 +1,71 @@
+package helper
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSafeFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{name: "plain", filename: "report.pdf", want: "report.pdf"},
+		{name: "directory", filename: "logs/app.log", want: "app.log"},
+		{name: "windows path", filename: `C:\Users\jane\report.pdf`, want: "report.pdf"},
+		{name: "traversal", filename: "../../etc/passwd", want: "passwd"},
+		{name: "spaces and brackets", filename: "my report (1).pdf", want: "my_report_1_.pdf"},
+		{name: "non ascii", filename: "café.txt", want: "caf_.txt"},
+		{name: "leading dot", filename: ".htaccess", want: "htaccess"},
+		{name: "empty", filename: "", want: "file"},
+		{name: "only unsafe characters", filename: "???", want: "file"},
+		{name: "too long keeps the end", filename: strings.Repeat("a", 120) + ".txt", want: strings.Repeat("a", 96) + ".txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SafeFilename(tt.filename); got != tt.want {
+				t.Errorf("SafeFilename(%q) = %q, want %q", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachmentObjectKey(t *testing.T) {
+	key, err := AttachmentObjectKey(42, "../my report.pdf")
+	if err != nil {
+		t.Fatalf("AttachmentObjectKey() error = %v", err)
+	}
+
+	if !regexp.MustCompile(`^tickets/42/[0-9a-f]{16}-my_report\.pdf$`).MatchString(key) {
+		t.Errorf("AttachmentObjectKey() = %q", key)
+	}
+}
+
+func TestCleanFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{name: "plain", filename: "report.pdf", want: "report.pdf"},
+		{name: "keeps spaces and unicode", filename: "Café menu (1).pdf", want: "Café menu (1).pdf"},
+		{name: "directory", filename: "../../etc/passwd", want: "passwd"},
+		{name: "windows path", filename: `C:\Users\jane\report.pdf`, want: "report.pdf"},
+		{name: "control characters", filename: "evil\r\nname\x00.txt", want: "evilname.txt"},
+		{name: "surrounding spaces", filename: "  notes.txt  ", want: "notes.txt"},
+		{name: "empty", filename: "", want: "file"},
+		{name: "root", filename: "/", want: "file"},
+		{name: "dot", filename: ".", want: "file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CleanFilename(tt.filename); got != tt.want {
+				t.Errorf("CleanFilename(%q) = %q, want %q", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package helper
+
+import (
+	"helpdesk-ticketing-system/internal/model"
+	"time"
+)
+
+// QuietHoursEnd returns when the quiet hours that now falls in end, or nil
+// when now is outside them or the user has none.
+func QuietHoursEnd(now time.Time, settings *model.NotificationSettings) *time.Time {
+	if settings == nil || settings.QuietHoursStart == "" || settings.QuietHoursEnd == "" {
+		return nil
+	}
+
+	start, err := time.Parse("15:04", settings.QuietHoursStart)
+	if err != nil {
+		return nil
+	}
+	end, err := time.Parse("15:04", settings.QuietHoursEnd)
+	if err != nil {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	current := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	endDay := local
+	switch {
+	case startMinute == endMinute:
+		return nil
+	case startMinute < endMinute:
+		if current < startMinute || current >= endMinute {
+			return nil
+		}
+	default:
+		// overnight window, e.g. 22:00 to 07:00
+		if current < startMinute && current >= endMinute {
+			return nil
+		}
+		if current >= startMinute {
+			endDay = local.AddDate(0, 0, 1)
+		}
+	}
+
+	until := time.Date(endDay.Year(), endDay.Month(), endDay.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+	return &until
+}
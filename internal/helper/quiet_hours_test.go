@@ -0,0 +1,120 @@
+package helper
+
+import (
+	"helpdesk-ticketing-system/internal/model"
+	"testing"
+	"time"
+)
+
+func TestQuietHoursEnd(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skip("time zone database not available:", err)
+	}
+
+	overnight := &model.NotificationSettings{Timezone: "UTC", QuietHoursStart: "22:00", QuietHoursEnd: "07:00"}
+	daytime := &model.NotificationSettings{Timezone: "UTC", QuietHoursStart: "12:00", QuietHoursEnd: "13:30"}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		settings *model.NotificationSettings
+		want     *time.Time
+	}{
+		{
+			name: "no settings",
+			now:  time.Date(2025, 6, 1, 23, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "no quiet hours",
+			now:      time.Date(2025, 6, 1, 23, 0, 0, 0, time.UTC),
+			settings: &model.NotificationSettings{Timezone: "UTC"},
+		},
+		{
+			name:     "invalid times",
+			now:      time.Date(2025, 6, 1, 23, 0, 0, 0, time.UTC),
+			settings: &model.NotificationSettings{Timezone: "UTC", QuietHoursStart: "late", QuietHoursEnd: "07:00"},
+		},
+		{
+			name:     "empty window",
+			now:      time.Date(2025, 6, 1, 22, 0, 0, 0, time.UTC),
+			settings: &model.NotificationSettings{Timezone: "UTC", QuietHoursStart: "22:00", QuietHoursEnd: "22:00"},
+		},
+		{
+			name:     "overnight before midnight",
+			now:      time.Date(2025, 6, 1, 23, 15, 0, 0, time.UTC),
+			settings: overnight,
+			want:     timePtr(time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:     "overnight after midnight",
+			now:      time.Date(2025, 6, 2, 3, 0, 0, 0, time.UTC),
+			settings: overnight,
+			want:     timePtr(time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:     "overnight at start",
+			now:      time.Date(2025, 6, 1, 22, 0, 0, 0, time.UTC),
+			settings: overnight,
+			want:     timePtr(time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:     "overnight at end",
+			now:      time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC),
+			settings: overnight,
+		},
+		{
+			name:     "overnight outside",
+			now:      time.Date(2025, 6, 2, 15, 0, 0, 0, time.UTC),
+			settings: overnight,
+		},
+		{
+			name:     "daytime inside",
+			now:      time.Date(2025, 6, 2, 12, 45, 0, 0, time.UTC),
+			settings: daytime,
+			want:     timePtr(time.Date(2025, 6, 2, 13, 30, 0, 0, time.UTC)),
+		},
+		{
+			name:     "daytime before",
+			now:      time.Date(2025, 6, 2, 11, 59, 0, 0, time.UTC),
+			settings: daytime,
+		},
+		{
+			name:     "user time zone",
+			now:      time.Date(2025, 6, 1, 16, 0, 0, 0, time.UTC), // 23:00 in Jakarta
+			settings: &model.NotificationSettings{Timezone: "Asia/Jakarta", QuietHoursStart: "22:00", QuietHoursEnd: "07:00"},
+			want:     timePtr(time.Date(2025, 6, 2, 7, 0, 0, 0, jakarta)),
+		},
+		{
+			name:     "unknown time zone falls back to UTC",
+			now:      time.Date(2025, 6, 1, 23, 0, 0, 0, time.UTC),
+			settings: &model.NotificationSettings{Timezone: "Mars/Olympus", QuietHoursStart: "22:00", QuietHoursEnd: "07:00"},
+			want:     timePtr(time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := QuietHoursEnd(tt.now, tt.settings)
+			assertTimePtr(t, got, tt.want)
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func assertTimePtr(t *testing.T, got *time.Time, want *time.Time) {
+	t.Helper()
+
+	switch {
+	case got == nil && want == nil:
+	case got == nil:
+		t.Errorf("got nil, want %v", *want)
+	case want == nil:
+		t.Errorf("got %v, want nil", *got)
+	case !got.Equal(*want):
+		t.Errorf("got %v, want %v", *got, *want)
+	}
+}
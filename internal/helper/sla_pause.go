@@ -0,0 +1,62 @@
+package helper
+
+import (
+	"time"
+
+	"helpdesk-ticketing-system/internal/model"
+)
+
+// PausedIntervals walks the ticket histories, oldest first, and returns the
+// periods the ticket spent in the pending status. The last interval has a
+// nil To while the ticket is still pending.
+func PausedIntervals(histories []model.TicketHistory) []model.PausedInterval {
+	var intervals []model.PausedInterval
+	var pausedAt *time.Time
+
+	for _, history := range histories {
+		changedAt := history.ChangedAt
+
+		if history.Status == model.TicketStatusPending {
+			if pausedAt == nil {
+				pausedAt = &changedAt
+			}
+			continue
+		}
+
+		if pausedAt != nil {
+			intervals = append(intervals, model.PausedInterval{
+				From: *pausedAt,
+				To:   &changedAt,
+			})
+			pausedAt = nil
+		}
+	}
+
+	if pausedAt != nil {
+		intervals = append(intervals, model.PausedInterval{From: *pausedAt})
+	}
+
+	for i := range intervals {
+		if intervals[i].To != nil {
+			intervals[i].Duration = FormatDuration(intervals[i].To.Sub(intervals[i].From))
+		}
+	}
+
+	return intervals
+}
+
+// PausedBusinessDuration sums the business time of the intervals. An open
+// interval is counted up to until.
+func PausedBusinessDuration(intervals []model.PausedInterval, until time.Time, calendar *model.BusinessCalendar) time.Duration {
+	var total time.Duration
+	for _, interval := range intervals {
+		to := until
+		if interval.To != nil {
+			to = *interval.To
+		}
+
+		total += BusinessDuration(interval.From, to, calendar)
+	}
+
+	return total
+}
@@ -6,16 +6,34 @@ import (
 	"helpdesk-ticketing-system/internal/model"
 )
 
-func GetUserID(ctx context.Context) (int64, error) {
+func GetClaims(ctx context.Context) (model.CustomClaims, error) {
 	val := ctx.Value(model.BearerAuthKey)
 	if val == nil {
-		return 0, errors.New("user claims not found in context")
+		return model.CustomClaims{}, errors.New("user claims not found in context")
 	}
 
 	claims, ok := val.(model.CustomClaims)
 	if !ok {
-		return 0, errors.New("invalid claims type in context")
+		return model.CustomClaims{}, errors.New("invalid claims type in context")
+	}
+
+	return claims, nil
+}
+
+func GetUserID(ctx context.Context) (int64, error) {
+	claims, err := GetClaims(ctx)
+	if err != nil {
+		return 0, err
 	}
 
 	return claims.UserID, nil
 }
+
+func GetUserRole(ctx context.Context) (string, error) {
+	claims, err := GetClaims(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return claims.Role, nil
+}
@@ -0,0 +1,45 @@
+package helper
+
+import "testing"
+
+// The expected values were computed with
+// printf '<timestamp>.<body>' | openssl dgst -sha256 -hmac '<secret>'.
+func TestSignWebhook(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      string
+		want      string
+	}{
+		{
+			name:      "event",
+			secret:    "whsec_test",
+			timestamp: 1718000000,
+			body:      `{"event":"ticket.created"}`,
+			want:      "sha256=f35e42b2aa7dc748849268615c425d4a99b1f921aa82cc13aaea6988b4fb7982",
+		},
+		{
+			name:      "timestamp is signed",
+			secret:    "whsec_test",
+			timestamp: 1718000001,
+			body:      `{"event":"ticket.created"}`,
+			want:      "sha256=33dfa2feba6e71b52f48e0bb9c3b24e9240de6a1056e40c0c6a3630c9baf750c",
+		},
+		{
+			name:      "empty secret and body",
+			secret:    "",
+			timestamp: 0,
+			body:      "",
+			want:      "sha256=b849d5a581847b281957065739df36df2463d1977ea8d6e1e4e6cf33fadc68c3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SignWebhook(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+				t.Errorf("SignWebhook() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,39 +2,131 @@ package model
 
 import (
 	"context"
+	"io"
 	"time"
 )
 
+// Scan states of an attachment. New attachments are quarantined until the
+// malware scanner has checked them.
+const (
+	AttachmentScanQuarantined = "quarantined"
+	AttachmentScanClean       = "clean"
+	AttachmentScanInfected    = "infected"
+)
+
+// Attachment is a file on a ticket. The content lives in blob storage under
+// ObjectKey; Filename is the name it was uploaded with. MimeType is detected
+// from the content, not taken from the client. ScanSignature names the
+// malware found in infected files.
 type Attachment struct {
-	ID         int64     `json:"id"`
-	TicketID   int64     `json:"ticket_id"`
-	FilePath   string    `json:"file_path"`
-	UploadedAt time.Time `json:"uploaded_at"`
+	ID            int64      `json:"id"`
+	TicketID      int64      `json:"ticket_id"`
+	ObjectKey     string     `json:"object_key"`
+	Filename      string     `json:"filename"`
+	MimeType      string     `json:"mime_type"`
+	Size          int64      `json:"size"`
+	SHA256        string     `json:"sha256" gorm:"column:sha256"`
+	ScanStatus    string     `json:"scan_status"`
+	ScanSignature string     `json:"scan_signature,omitempty"`
+	ScannedAt     *time.Time `json:"scanned_at,omitempty"`
+	UploadedBy    int64      `json:"uploaded_by,omitempty" gorm:"default:null"`
+	UploadedAt    time.Time  `json:"uploaded_at"`
 }
 
 type AttachmentResponse struct {
 	ID         int64     `json:"id"`
 	TicketID   int64     `json:"ticket_id"`
-	FilePath   string    `json:"file_path"`
+	ObjectKey  string    `json:"object_key"`
+	Filename   string    `json:"filename"`
 	UploadedAt time.Time `json:"uploaded_at"`
 }
 
 type AttachmentResponseForTicket struct {
-	FilePath   string    `json:"file_path"`
+	ID         int64     `json:"id"`
+	Filename   string    `json:"filename"`
+	MimeType   string    `json:"mime_type"`
+	Size       int64     `json:"size"`
+	ScanStatus string    `json:"scan_status"`
 	UploadedAt time.Time `json:"uploaded_at"`
 }
 
-type CreateAttachmentInput struct {
-	TicketID int64  `json:"ticket_id" validate:"required"`
-	FilePath string `json:"file_path" validate:"required"`
+// UploadAttachmentInput describes an upload. Size must be the exact length
+// of Content; ContentType is what the client claimed and is only passed on
+// to blob storage when sniffing the content finds nothing more specific.
+type UploadAttachmentInput struct {
+	TicketID    int64     `validate:"required"`
+	Filename    string    `validate:"required,max=255"`
+	ContentType string    `validate:"max=255"`
+	Size        int64     `validate:"min=0"`
+	Content     io.Reader `validate:"required"`
+}
+
+// AttachmentDownload is an attachment opened for reading. Content must be
+// closed once served.
+type AttachmentDownload struct {
+	Attachment  *Attachment
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+	Content     io.ReadSeekCloser
+}
+
+// SignedDownloadURL lets a client without the access token, such as a
+// browser tab or media player, fetch an attachment until ExpiresAt. URL is
+// relative to the API host.
+type SignedDownloadURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RescanAttachmentsInput limits how many quarantined attachments one
+// RescanQuarantined call queues again; 0 uses the default.
+type RescanAttachmentsInput struct {
+	Limit int `json:"limit" validate:"min=0,max=1000"`
+}
+
+// AttachmentScanMessage is the RabbitMQ message that asks the worker to scan
+// an attachment.
+type AttachmentScanMessage struct {
+	AttachmentID int64 `json:"attachment_id"`
 }
 
 type IAttachmentRepository interface {
+	FindById(ctx context.Context, id int64) (*Attachment, error)
 	FindAllByTicketID(ctx context.Context, ticketID int64) ([]*Attachment, error)
-	Create(ctx context.Context, attachment Attachment) error
+	FindAllByTicketIDs(ctx context.Context, ticketIDs []int64) ([]*Attachment, error)
+	Create(ctx context.Context, attachment *Attachment) error
+	// FindQuarantined returns attachments uploaded before the given time that
+	// are still waiting for their scan, oldest first.
+	FindQuarantined(ctx context.Context, uploadedBefore time.Time, limit int) ([]*Attachment, error)
+	// UpdateScanResult stores the scan result if the attachment is still
+	// quarantined and reports whether it was.
+	UpdateScanResult(ctx context.Context, attachment *Attachment) (bool, error)
 }
 
 type IAttachmentUsecase interface {
 	FindAllByTicketID(ctx context.Context, ticketID int64) ([]*Attachment, error)
-	Create(ctx context.Context, in CreateAttachmentInput) error
+	// Upload stores the content in blob storage and records it on the ticket.
+	// Files over the size limit return ErrFileTooLarge and files of a type
+	// that is not allowed return ErrFileTypeNotAllowed. The attachment is
+	// quarantined until Scan has checked it.
+	Upload(ctx context.Context, in UploadAttachmentInput) (*Attachment, error)
+	// Scan checks a quarantined attachment for malware and marks it clean or
+	// infected. Attachments that were already scanned are returned as they
+	// are.
+	Scan(ctx context.Context, id int64) (*Attachment, error)
+	// RescanQuarantined queues another scan for attachments whose scan jobs
+	// ran out of retries, such as during a long clamd outage. Only admins
+	// may call it.
+	RescanQuarantined(ctx context.Context, in RescanAttachmentsInput) (int, error)
+	// Download opens the attachment if the caller can see its ticket. It
+	// returns ErrAttachmentQuarantined until the attachment is scanned and
+	// ErrAttachmentInfected once malware was found.
+	Download(ctx context.Context, id int64) (*AttachmentDownload, error)
+	// SignDownloadURL returns a short-lived link to Download the attachment
+	// without authentication.
+	SignDownloadURL(ctx context.Context, id int64) (*SignedDownloadURL, error)
+	// DownloadSigned opens the attachment for a link from SignDownloadURL.
+	// Invalid or expired links return ErrForbidden.
+	DownloadSigned(ctx context.Context, id int64, expires int64, signature string) (*AttachmentDownload, error)
 }
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Blob storage drivers.
+const (
+	BlobStorageLocal = "local"
+	BlobStorageS3    = "s3"
+)
+
+type BlobInfo struct {
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// IBlobStorage keeps file contents by object key. Keys are slash separated
+// paths such as "tickets/12/3f9c-report.pdf".
+type IBlobStorage interface {
+	// Put stores size bytes read from r under key, replacing any blob there.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the blob for reading. A missing key returns ErrBlobNotFound.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// GetRange opens length bytes of the blob starting at offset.
+	GetRange(ctx context.Context, key string, offset int64, length int64) (io.ReadCloser, error)
+	Stat(ctx context.Context, key string) (*BlobInfo, error)
+	Delete(ctx context.Context, key string) error
+}
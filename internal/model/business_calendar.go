@@ -0,0 +1,81 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// BusinessCalendar describes when the support team is on shift. SLA due dates
+// and overdue durations only count time inside its business hours.
+type BusinessCalendar struct {
+	ID        int64             `json:"id"`
+	Name      string            `json:"name"`
+	Timezone  string            `json:"timezone"`
+	IsDefault bool              `json:"is_default"`
+	Hours     []BusinessHour    `json:"hours" gorm:"foreignKey:BusinessCalendarID"`
+	Holidays  []BusinessHoliday `json:"holidays" gorm:"foreignKey:BusinessCalendarID"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	DeletedAt *time.Time        `json:"-"`
+}
+
+// BusinessHour is one working window on a weekday (0 = Sunday), with
+// StartTime and EndTime in "15:04" format in the calendar timezone.
+type BusinessHour struct {
+	ID                 int64  `json:"id"`
+	BusinessCalendarID int64  `json:"business_calendar_id"`
+	Weekday            int    `json:"weekday"`
+	StartTime          string `json:"start_time"`
+	EndTime            string `json:"end_time"`
+}
+
+type BusinessHoliday struct {
+	ID                 int64     `json:"id"`
+	BusinessCalendarID int64     `json:"business_calendar_id"`
+	Date               time.Time `json:"date"`
+	Name               string    `json:"name"`
+}
+
+type BusinessHourInput struct {
+	Weekday   int    `json:"weekday" validate:"min=0,max=6"`
+	StartTime string `json:"start_time" validate:"required,datetime=15:04"`
+	EndTime   string `json:"end_time" validate:"required,datetime=15:04"`
+}
+
+type BusinessHolidayInput struct {
+	Date string `json:"date" validate:"required,datetime=2006-01-02"`
+	Name string `json:"name"`
+}
+
+type CreateBusinessCalendarInput struct {
+	Name      string                 `json:"name" validate:"required"`
+	Timezone  string                 `json:"timezone" validate:"required,timezone"`
+	IsDefault bool                   `json:"is_default"`
+	Hours     []BusinessHourInput    `json:"hours" validate:"required,min=1,dive"`
+	Holidays  []BusinessHolidayInput `json:"holidays" validate:"dive"`
+}
+
+type UpdateBusinessCalendarInput struct {
+	Name      string                 `json:"name" validate:"required"`
+	Timezone  string                 `json:"timezone" validate:"required,timezone"`
+	IsDefault bool                   `json:"is_default"`
+	Hours     []BusinessHourInput    `json:"hours" validate:"required,min=1,dive"`
+	Holidays  []BusinessHolidayInput `json:"holidays" validate:"dive"`
+}
+
+type IBusinessCalendarRepository interface {
+	FindAll(ctx context.Context) ([]*BusinessCalendar, error)
+	FindById(ctx context.Context, id int64) (*BusinessCalendar, error)
+	FindDefault(ctx context.Context) (*BusinessCalendar, error)
+	Create(ctx context.Context, calendar BusinessCalendar) (*BusinessCalendar, error)
+	Update(ctx context.Context, calendar BusinessCalendar) (*BusinessCalendar, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type IBusinessCalendarUsecase interface {
+	FindAll(ctx context.Context) ([]*BusinessCalendar, error)
+	FindById(ctx context.Context, id int64) (*BusinessCalendar, error)
+	Create(ctx context.Context, in CreateBusinessCalendarInput) (*BusinessCalendar, error)
+	Update(ctx context.Context, id int64, in UpdateBusinessCalendarInput) (*BusinessCalendar, error)
+	Delete(ctx context.Context, id int64) error
+}
@@ -34,6 +34,7 @@ type ICommentRepository interface {
 	FindAll(ctx context.Context, comment Comment) ([]*Comment, error)
 	FindById(ctx context.Context, id int64) (*Comment, error)
 	FindAllByTicketID(ctx context.Context, ticketID int64) ([]*Comment, error)
+	FindAllByTicketIDs(ctx context.Context, ticketIDs []int64) ([]*Comment, error)
 	Create(ctx context.Context, comment Comment) (*Comment, error)
 	Update(ctx context.Context, comment Comment) (*Comment, error)
 	Delete(ctx context.Context, id int64) error
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// Domain events raised by the ticket lifecycle. The names double as the
+// routing keys on the domain event exchange and as the ticket event types
+// pushed to clients and webhooks.
+const (
+	DomainEventTicketCreated       = "ticket.created"
+	DomainEventTicketUpdated       = "ticket.updated"
+	DomainEventTicketStatusChanged = "ticket.status_changed"
+	DomainEventTicketAssigned      = "ticket.assigned"
+	DomainEventTicketSLAAtRisk     = "ticket.sla_at_risk"
+	DomainEventCommentAdded        = "comment.added"
+	DomainEventAttachmentAdded     = "attachment.added"
+	DomainEventAttachmentInfected  = "attachment.infected"
+)
+
+// DomainEventNames lists every domain event.
+var DomainEventNames = []string{
+	DomainEventTicketCreated,
+	DomainEventTicketUpdated,
+	DomainEventTicketStatusChanged,
+	DomainEventTicketAssigned,
+	DomainEventTicketSLAAtRisk,
+	DomainEventCommentAdded,
+	DomainEventAttachmentAdded,
+	DomainEventAttachmentInfected,
+}
+
+// DomainEventMeta is embedded in every domain event. The bus fills in ID and
+// OccurredAt when the event is published.
+type DomainEventMeta struct {
+	ID         string    `json:"id"`
+	ActorID    int64     `json:"actor_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (m *DomainEventMeta) EventMeta() *DomainEventMeta {
+	return m
+}
+
+// DomainEvent is a change to a ticket that other parts of the system react
+// to. Events are published as pointers to the typed structs below.
+type DomainEvent interface {
+	EventName() string
+	EventTicket() *Ticket
+	EventMeta() *DomainEventMeta
+}
+
+type TicketCreated struct {
+	DomainEventMeta
+	Ticket *Ticket `json:"ticket"`
+}
+
+// TicketUpdated is raised for every saved edit, next to the more specific
+// status and assignment events.
+type TicketUpdated struct {
+	DomainEventMeta
+	Ticket   *Ticket `json:"ticket"`
+	Previous *Ticket `json:"previous"`
+}
+
+type TicketStatusChanged struct {
+	DomainEventMeta
+	Ticket *Ticket `json:"ticket"`
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+}
+
+type TicketAssigned struct {
+	DomainEventMeta
+	Ticket           *Ticket `json:"ticket"`
+	PreviousAssignee int64   `json:"previous_assignee"`
+}
+
+// TicketSLAAtRisk is raised when the first response or resolution of a
+// ticket is about to fall due. Target is one of the SLATarget constants.
+type TicketSLAAtRisk struct {
+	DomainEventMeta
+	Ticket *Ticket   `json:"ticket"`
+	Target string    `json:"target"`
+	DueBy  time.Time `json:"due_by"`
+}
+
+type CommentAdded struct {
+	DomainEventMeta
+	Ticket  *Ticket  `json:"ticket"`
+	Comment *Comment `json:"comment"`
+}
+
+type AttachmentAdded struct {
+	DomainEventMeta
+	Ticket     *Ticket     `json:"ticket"`
+	Attachment *Attachment `json:"attachment"`
+}
+
+// AttachmentInfected is raised when the malware scanner finds a signature in
+// an attachment.
+type AttachmentInfected struct {
+	DomainEventMeta
+	Ticket     *Ticket     `json:"ticket"`
+	Attachment *Attachment `json:"attachment"`
+}
+
+func (e *TicketCreated) EventName() string       { return DomainEventTicketCreated }
+func (e *TicketUpdated) EventName() string       { return DomainEventTicketUpdated }
+func (e *TicketStatusChanged) EventName() string { return DomainEventTicketStatusChanged }
+func (e *TicketAssigned) EventName() string      { return DomainEventTicketAssigned }
+func (e *TicketSLAAtRisk) EventName() string     { return DomainEventTicketSLAAtRisk }
+func (e *CommentAdded) EventName() string        { return DomainEventCommentAdded }
+func (e *AttachmentAdded) EventName() string     { return DomainEventAttachmentAdded }
+func (e *AttachmentInfected) EventName() string  { return DomainEventAttachmentInfected }
+
+func (e *TicketCreated) EventTicket() *Ticket       { return e.Ticket }
+func (e *TicketUpdated) EventTicket() *Ticket       { return e.Ticket }
+func (e *TicketStatusChanged) EventTicket() *Ticket { return e.Ticket }
+func (e *TicketAssigned) EventTicket() *Ticket      { return e.Ticket }
+func (e *TicketSLAAtRisk) EventTicket() *Ticket     { return e.Ticket }
+func (e *CommentAdded) EventTicket() *Ticket        { return e.Ticket }
+func (e *AttachmentAdded) EventTicket() *Ticket     { return e.Ticket }
+func (e *AttachmentInfected) EventTicket() *Ticket  { return e.Ticket }
+
+// DomainEventMessage is the body published to the domain event exchange.
+type DomainEventMessage struct {
+	Name  string      `json:"name"`
+	Event DomainEvent `json:"event"`
+}
+
+type DomainEventHandler func(ctx context.Context, event DomainEvent) error
+
+type IEventBus interface {
+	// Subscribe runs handler inside the publisher's transaction; an error
+	// rolls the change back.
+	Subscribe(name string, handler DomainEventHandler)
+	// SubscribeAfterCommit runs handler once the transaction commits. Errors
+	// are logged.
+	SubscribeAfterCommit(name string, handler DomainEventHandler)
+	Publish(ctx context.Context, events ...DomainEvent) error
+}
@@ -0,0 +1,20 @@
+package model
+
+import "errors"
+
+var (
+	ErrForbidden             = errors.New("access denied")
+	ErrInvalidRefreshToken   = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused    = errors.New("refresh token reuse detected")
+	ErrInvalidTransition     = errors.New("status transition is not allowed")
+	ErrInvalidFilter         = errors.New("invalid filter")
+	ErrAutoReply             = errors.New("automatic reply ignored")
+	ErrInboundRejected       = errors.New("inbound message rejected")
+	ErrWebhookGone           = errors.New("webhook subscription no longer exists")
+	ErrBlobNotFound          = errors.New("blob not found")
+	ErrTicketNotFound        = errors.New("ticket not found")
+	ErrFileTooLarge          = errors.New("file is too large")
+	ErrFileTypeNotAllowed    = errors.New("file type is not allowed")
+	ErrAttachmentQuarantined = errors.New("attachment has not been scanned yet")
+	ErrAttachmentInfected    = errors.New("attachment is infected")
+)
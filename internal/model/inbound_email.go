@@ -0,0 +1,52 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// InboundEmail is a message received by the inbound mail gateway. Message IDs
+// are stored without their angle brackets.
+type InboundEmail struct {
+	MessageID     string
+	InReplyTo     string
+	References    []string
+	From          string
+	FromName      string
+	Subject       string
+	Text          string
+	HTML          string
+	AutoSubmitted bool
+	// AuthenticationResults holds the raw Authentication-Results headers,
+	// newest first.
+	AuthenticationResults []string
+	Attachments           []InboundAttachment
+}
+
+type InboundAttachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// InboundMessage records an inbound email and the ticket or comment it was
+// turned into, so replies can be threaded and redelivered mail is skipped.
+type InboundMessage struct {
+	ID        int64     `json:"id"`
+	MessageID string    `json:"message_id"`
+	TicketID  int64     `json:"ticket_id"`
+	CommentID *int64    `json:"comment_id,omitempty"`
+	UserID    int64     `json:"user_id"`
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type IInboundMessageRepository interface {
+	FindByMessageID(ctx context.Context, messageID string) (*InboundMessage, error)
+	FindTicketIDByMessageIDs(ctx context.Context, messageIDs []string) (int64, error)
+	Create(ctx context.Context, message *InboundMessage) error
+}
+
+type IInboundEmailUsecase interface {
+	Process(ctx context.Context, email *InboundEmail) (*InboundMessage, error)
+}
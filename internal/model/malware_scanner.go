@@ -0,0 +1,23 @@
+package model
+
+import (
+	"context"
+	"io"
+)
+
+const (
+	MalwareScannerClamd = "clamd"
+	MalwareScannerFake  = "fake"
+)
+
+// ScanResult is the verdict on one file. Signature names the malware found.
+type ScanResult struct {
+	Infected  bool
+	Signature string
+}
+
+// IMalwareScanner checks file content for malware. An error means the file
+// could not be scanned, not that it is infected.
+type IMalwareScanner interface {
+	Scan(ctx context.Context, r io.Reader) (*ScanResult, error)
+}
@@ -5,21 +5,214 @@ import (
 	"time"
 )
 
+// Notification events, each with its own email templates.
+const (
+	NotificationEventGeneric             = "generic"
+	NotificationEventTicketCreated       = "ticket_created"
+	NotificationEventTicketAssigned      = "ticket_assigned"
+	NotificationEventTicketCommented     = "ticket_commented"
+	NotificationEventTicketStatusChanged = "ticket_status_changed"
+	NotificationEventSLABreachWarning    = "sla_breach_warning"
+	NotificationEventAttachmentInfected  = "attachment_infected"
+	NotificationEventDigest              = "digest"
+)
+
+// Notification channels. Each has its own queue and delivery driver, except
+// in-app notifications, which are only stored for the user's inbox.
+const (
+	NotificationChannelEmail   = "email"
+	NotificationChannelWebhook = "webhook"
+	NotificationChannelChat    = "chat"
+	NotificationChannelInApp   = "in_app"
+)
+
+const (
+	NotificationStatusPending  = "pending"
+	NotificationStatusSent     = "sent"
+	NotificationStatusRetrying = "retrying"
+	NotificationStatusFailed   = "failed"
+	NotificationStatusHeld     = "held"
+	NotificationStatusDigested = "digested"
+)
+
 type Notification struct {
+	ID         int64            `json:"id"`
+	TicketID   int64            `json:"ticket_id" gorm:"default:null"`
+	UserID     int64            `json:"user_id"`
+	Email      string           `json:"email"`
+	Channel    string           `json:"channel"`
+	Target     string           `json:"target,omitempty"`
+	Subject    string           `json:"subject"`
+	Message    string           `json:"message"`
+	Event      string           `json:"event"`
+	Data       NotificationData `json:"data" gorm:"serializer:json"`
+	Status     string           `json:"status"`
+	Attempts   int              `json:"attempts"`
+	LastError  string           `json:"last_error,omitempty"`
+	SentAt     *time.Time       `json:"sent_at,omitempty"`
+	HeldUntil  *time.Time       `json:"held_until,omitempty"`
+	DigestID   *int64           `json:"digest_id,omitempty"`
+	DigestedAt *time.Time       `json:"digested_at,omitempty"`
+	ReadAt     *time.Time       `json:"read_at"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+// NotificationData is the ticket context the templates of an event render.
+type NotificationData struct {
+	TicketID       int64      `json:"ticket_id,omitempty"`
+	TicketTitle    string     `json:"ticket_title,omitempty"`
+	TicketURL      string     `json:"ticket_url,omitempty"`
+	Description    string     `json:"description,omitempty"`
+	Priority       string     `json:"priority,omitempty"`
+	Status         string     `json:"status,omitempty"`
+	PreviousStatus string     `json:"previous_status,omitempty"`
+	DueBy          *time.Time `json:"due_by,omitempty"`
+	SLATarget      string     `json:"sla_target,omitempty"`
+	ActorName      string     `json:"actor_name,omitempty"`
+	Comment        string     `json:"comment,omitempty"`
+	Attachment     string     `json:"attachment,omitempty"`
+	Signature      string     `json:"signature,omitempty"`
+	// Digest is only set on digest notifications.
+	Digest *NotificationDigest `json:"digest,omitempty"`
+}
+
+// NotificationDigest summarises held notifications grouped by ticket.
+type NotificationDigest struct {
+	New     int                        `json:"new"`
+	Updated int                        `json:"updated"`
+	Overdue int                        `json:"overdue"`
+	Tickets []NotificationDigestTicket `json:"tickets"`
+}
+
+// NotificationDigestTicket is the latest known state of a ticket in a digest
+// along with the notifications about it.
+type NotificationDigestTicket struct {
+	TicketID    int64                    `json:"ticket_id"`
+	TicketTitle string                   `json:"ticket_title"`
+	TicketURL   string                   `json:"ticket_url"`
+	Priority    string                   `json:"priority"`
+	Status      string                   `json:"status"`
+	DueBy       *time.Time               `json:"due_by,omitempty"`
+	New         bool                     `json:"new"`
+	Overdue     bool                     `json:"overdue"`
+	Items       []NotificationDigestItem `json:"items"`
+}
+
+type NotificationDigestItem struct {
+	Event     string    `json:"event"`
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RenderedEmail is a notification rendered from its event templates.
+type RenderedEmail struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+	// MessageID lets replies to the email be threaded back to its ticket.
+	MessageID string `json:"-"`
+}
+
+type PreviewTemplateInput struct {
+	Event   string            `json:"event" validate:"required,oneof=generic ticket_created ticket_assigned ticket_commented ticket_status_changed sla_breach_warning attachment_infected digest"`
+	Subject string            `json:"subject"`
+	Message string            `json:"message"`
+	Data    *NotificationData `json:"data"`
+}
+
+// NotificationInboxParam pages through the caller's in-app notifications,
+// newest first. Cursor is the NextCursor of the previous page.
+type NotificationInboxParam struct {
+	Limit      int64  `json:"limit" validate:"min=0,max=100"`
+	Cursor     string `json:"cursor"`
+	UnreadOnly bool   `json:"unread_only"`
+}
+
+type NotificationPageMeta struct {
+	PageMeta
+	Unread int64 `json:"unread"`
+}
+
+type NotificationPage struct {
+	Notifications []*Notification      `json:"notifications"`
+	Meta          NotificationPageMeta `json:"meta"`
+}
+
+// NotificationDelivery is the outcome of one attempt to deliver a
+// notification.
+type NotificationDelivery struct {
+	Status    string
+	Attempts  int
+	LastError string
+}
+
+// NotificationChannelSetting sends a user's notifications to a channel. Target
+// is the webhook URL for webhook and chat, and an optional address that
+// replaces the account email for email.
+type NotificationChannelSetting struct {
 	ID        int64     `json:"id"`
-	TicketID  int64     `json:"ticket_id"`
 	UserID    int64     `json:"user_id"`
-	Email     string    `json:"email"`
-	Subject   string    `json:"subject"`
-	Message   string    `json:"message"`
-	Status    string    `json:"status"`
+	Channel   string    `json:"channel"`
+	Target    string    `json:"target"`
 	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateNotificationChannelInput struct {
+	Channel string `json:"channel" validate:"required,oneof=email webhook chat"`
+	Target  string `json:"target" validate:"max=2048"`
+}
+
+// INotificationDriver delivers notifications of one channel.
+type INotificationDriver interface {
+	Deliver(ctx context.Context, notification *Notification) error
+}
+
+type RequeueDeadLettersInput struct {
+	Channel string `json:"channel" validate:"omitempty,oneof=email webhook chat"`
+	Limit   int    `json:"limit" validate:"min=0,max=1000"`
 }
 
 type INotificationRepository interface {
 	Save(ctx context.Context, notification *Notification) error
+	UpdateDelivery(ctx context.Context, id int64, delivery NotificationDelivery) error
+	FindHeldDue(ctx context.Context, now time.Time) ([]*Notification, error)
+	// LockHeld locks the notifications among ids that are still held,
+	// skipping rows another transaction has locked. It must run inside a
+	// unit of work.
+	LockHeld(ctx context.Context, ids []int64) ([]*Notification, error)
+	MarkDigested(ctx context.Context, ids []int64, digestID int64) error
+	FindById(ctx context.Context, id int64) (*Notification, error)
+	FindInbox(ctx context.Context, userID int64, param NotificationInboxParam) (*NotificationPage, error)
+	MarkRead(ctx context.Context, id int64) error
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+}
+
+type INotificationChannelRepository interface {
+	FindAllByUserID(ctx context.Context, userID int64) ([]*NotificationChannelSetting, error)
+	FindById(ctx context.Context, id int64) (*NotificationChannelSetting, error)
+	Create(ctx context.Context, setting NotificationChannelSetting) (*NotificationChannelSetting, error)
+	Delete(ctx context.Context, id int64) error
 }
 
 type INotificationUsecase interface {
 	SendNotification(ctx context.Context, notification *Notification) error
+	NotifyUser(ctx context.Context, user *User, notification Notification) error
+	// NotifyTicketEvent notifies the people a ticket event concerns, other
+	// than whoever caused it. It is subscribed to the event bus.
+	NotifyTicketEvent(ctx context.Context, event DomainEvent) error
+	FindChannels(ctx context.Context) ([]*NotificationChannelSetting, error)
+	CreateChannel(ctx context.Context, in CreateNotificationChannelInput) (*NotificationChannelSetting, error)
+	DeleteChannel(ctx context.Context, id int64) error
+	GetPreferences(ctx context.Context) (*NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, in UpdateNotificationPreferencesInput) (*NotificationPreferences, error)
+	SendDigests(ctx context.Context) (int, error)
+	FindInbox(ctx context.Context, param NotificationInboxParam) (*NotificationPage, error)
+	MarkRead(ctx context.Context, id int64) error
+	MarkAllRead(ctx context.Context) (int64, error)
+	RecordDelivery(ctx context.Context, id int64, delivery NotificationDelivery) error
+	RequeueDeadLetters(ctx context.Context, in RequeueDeadLettersInput) (int, error)
+	RenderEmail(notification *Notification) (*RenderedEmail, error)
+	PreviewTemplate(ctx context.Context, in PreviewTemplateInput) (*RenderedEmail, error)
 }
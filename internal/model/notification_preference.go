@@ -0,0 +1,69 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// NotificationPreference turns one event off or on for one channel. Events
+// without a preference row are delivered.
+type NotificationPreference struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Event     string    `json:"event"`
+	Channel   string    `json:"channel"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Digest modes. Outside of off, non-urgent notifications are held and sent
+// together at the start of every hour or once a day at DigestHour.
+const (
+	NotificationDigestOff    = "off"
+	NotificationDigestHourly = "hourly"
+	NotificationDigestDaily  = "daily"
+)
+
+// NotificationSettings holds the quiet hours of a user as "15:04" times in
+// their timezone. A window whose end is before its start runs overnight.
+type NotificationSettings struct {
+	UserID          int64     `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Timezone        string    `json:"timezone"`
+	QuietHoursStart string    `json:"quiet_hours_start"`
+	QuietHoursEnd   string    `json:"quiet_hours_end"`
+	DigestMode      string    `json:"digest_mode"`
+	DigestHour      int       `json:"digest_hour"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type NotificationPreferences struct {
+	Settings    *NotificationSettings     `json:"settings"`
+	Preferences []*NotificationPreference `json:"preferences"`
+}
+
+type NotificationPreferenceInput struct {
+	Event   string `json:"event" validate:"required,oneof=ticket_created ticket_assigned ticket_commented ticket_status_changed sla_breach_warning attachment_infected"`
+	Channel string `json:"channel" validate:"required,oneof=email webhook chat in_app"`
+	Enabled bool   `json:"enabled"`
+}
+
+// UpdateNotificationPreferencesInput replaces the preferences of the caller.
+// Leaving both quiet hour times empty turns quiet hours off, and leaving the
+// digest mode empty turns digests off. Leaving the digest hour out keeps the
+// one already set.
+type UpdateNotificationPreferencesInput struct {
+	Timezone        string                        `json:"timezone" validate:"omitempty,timezone"`
+	QuietHoursStart string                        `json:"quiet_hours_start" validate:"required_with=QuietHoursEnd,omitempty,datetime=15:04"`
+	QuietHoursEnd   string                        `json:"quiet_hours_end" validate:"required_with=QuietHoursStart,omitempty,datetime=15:04"`
+	DigestMode      string                        `json:"digest_mode" validate:"omitempty,oneof=off hourly daily"`
+	DigestHour      *int                          `json:"digest_hour" validate:"omitempty,min=0,max=23"`
+	Preferences     []NotificationPreferenceInput `json:"preferences" validate:"dive"`
+}
+
+type INotificationPreferenceRepository interface {
+	FindSettings(ctx context.Context, userID int64) (*NotificationSettings, error)
+	FindAllByUserID(ctx context.Context, userID int64) ([]*NotificationPreference, error)
+	Save(ctx context.Context, settings NotificationSettings, preferences []NotificationPreference) error
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	OutboxStatusPending    = "pending"
+	OutboxStatusDispatched = "dispatched"
+	OutboxStatusFailed     = "failed"
+)
+
+// OutboxMessage is a RabbitMQ message written in the same transaction as the
+// change that caused it. The relay publishes pending rows once they are
+// available and gives up after the configured number of attempts.
+type OutboxMessage struct {
+	ID           int64      `json:"id"`
+	Exchange     string     `json:"exchange"`
+	RoutingKey   string     `json:"routing_key"`
+	ContentType  string     `json:"content_type"`
+	Payload      []byte     `json:"payload"`
+	Status       string     `json:"status"`
+	Attempts     int        `json:"attempts"`
+	LastError    string     `json:"last_error,omitempty"`
+	AvailableAt  time.Time  `json:"available_at"`
+	DispatchedAt *time.Time `json:"dispatched_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type IOutboxRepository interface {
+	Create(ctx context.Context, message OutboxMessage) (*OutboxMessage, error)
+	// FindPending locks up to limit available rows for the transaction in
+	// ctx, skipping rows another relay already holds.
+	FindPending(ctx context.Context, limit int) ([]*OutboxMessage, error)
+	MarkDispatched(ctx context.Context, id int64) error
+	MarkRetry(ctx context.Context, message OutboxMessage) error
+}
+
+type IOutboxUsecase interface {
+	Enqueue(ctx context.Context, exchange string, routingKey string, payload interface{}) error
+	// Relay publishes one batch of pending messages and returns how many
+	// were dispatched.
+	Relay(ctx context.Context) (int, error)
+}
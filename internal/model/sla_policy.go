@@ -0,0 +1,79 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// SLAPolicy holds the response and resolution targets for a set of tickets.
+// Empty CustomerID, Category and Queue act as wildcards, so a policy without
+// any of them is the catch-all default. Without a BusinessCalendarID the
+// default business calendar is used.
+type SLAPolicy struct {
+	ID                 int64       `json:"id"`
+	Name               string      `json:"name"`
+	CustomerID         *int64      `json:"customer_id,omitempty"`
+	Category           string      `json:"category,omitempty"`
+	Queue              string      `json:"queue,omitempty"`
+	BusinessCalendarID *int64      `json:"business_calendar_id,omitempty"`
+	Targets            []SLATarget `json:"targets" gorm:"foreignKey:SLAPolicyID"`
+	CreatedAt          time.Time   `json:"created_at"`
+	UpdatedAt          time.Time   `json:"updated_at"`
+	DeletedAt          *time.Time  `json:"-"`
+}
+
+// The two SLA targets a ticket is measured against.
+const (
+	SLATargetFirstResponse = "first_response"
+	SLATargetResolution    = "resolution"
+)
+
+type SLATarget struct {
+	ID                   int64  `json:"id"`
+	SLAPolicyID          int64  `json:"sla_policy_id"`
+	Priority             string `json:"priority"`
+	FirstResponseMinutes int64  `json:"first_response_minutes"`
+	ResolutionMinutes    int64  `json:"resolution_minutes"`
+}
+
+type SLATargetInput struct {
+	Priority             string `json:"priority" validate:"required,oneof=high medium low very_low"`
+	FirstResponseMinutes int64  `json:"first_response_minutes" validate:"required,gt=0"`
+	ResolutionMinutes    int64  `json:"resolution_minutes" validate:"required,gt=0,gtefield=FirstResponseMinutes"`
+}
+
+type CreateSLAPolicyInput struct {
+	Name               string           `json:"name" validate:"required"`
+	CustomerID         *int64           `json:"customer_id"`
+	Category           string           `json:"category"`
+	Queue              string           `json:"queue"`
+	BusinessCalendarID *int64           `json:"business_calendar_id"`
+	Targets            []SLATargetInput `json:"targets" validate:"required,min=1,dive"`
+}
+
+type UpdateSLAPolicyInput struct {
+	Name               string           `json:"name" validate:"required"`
+	CustomerID         *int64           `json:"customer_id"`
+	Category           string           `json:"category"`
+	Queue              string           `json:"queue"`
+	BusinessCalendarID *int64           `json:"business_calendar_id"`
+	Targets            []SLATargetInput `json:"targets" validate:"required,min=1,dive"`
+}
+
+type ISLAPolicyRepository interface {
+	FindAll(ctx context.Context) ([]*SLAPolicy, error)
+	FindById(ctx context.Context, id int64) (*SLAPolicy, error)
+	Create(ctx context.Context, policy SLAPolicy) (*SLAPolicy, error)
+	Update(ctx context.Context, policy SLAPolicy) (*SLAPolicy, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type ISLAPolicyUsecase interface {
+	FindAll(ctx context.Context) ([]*SLAPolicy, error)
+	FindById(ctx context.Context, id int64) (*SLAPolicy, error)
+	Create(ctx context.Context, in CreateSLAPolicyInput) (*SLAPolicy, error)
+	Update(ctx context.Context, id int64, in UpdateSLAPolicyInput) (*SLAPolicy, error)
+	Delete(ctx context.Context, id int64) error
+	ApplyToTicket(ctx context.Context, ticket *Ticket) error
+	CalendarFor(ctx context.Context, slaPolicyID *int64) (*BusinessCalendar, error)
+}
@@ -5,71 +5,179 @@ import (
 	"time"
 )
 
+const (
+	TicketStatusOpen       = "open"
+	TicketStatusInProgress = "in_progress"
+	TicketStatusPending    = "pending"
+	TicketStatusResolved   = "resolved"
+	TicketStatusClosed     = "closed"
+)
+
+// TicketOverdueCondition is the SQL form of TicketMissedDueDate, for
+// filtering overdue tickets. Both placeholders take the current time.
+const TicketOverdueCondition = "status IN ('" + TicketStatusOpen + "', '" + TicketStatusInProgress + "') AND " +
+	"(due_by < ? OR (first_responded_at IS NULL AND first_response_due_by < ?))"
+
+// TicketMissedDueDate returns the earliest due date an open or in-progress
+// ticket has missed by now: its resolution due date, or its first-response
+// due date while nobody has responded yet. It returns nil when the ticket is
+// not overdue. Keep it in step with TicketOverdueCondition.
+func TicketMissedDueDate(status string, dueBy *time.Time, firstResponseDueBy *time.Time, firstRespondedAt *time.Time, now time.Time) *time.Time {
+	if status != TicketStatusOpen && status != TicketStatusInProgress {
+		return nil
+	}
+
+	var missed *time.Time
+	if dueBy != nil && dueBy.Before(now) {
+		missed = dueBy
+	}
+	if firstRespondedAt == nil && firstResponseDueBy != nil && firstResponseDueBy.Before(now) &&
+		(missed == nil || firstResponseDueBy.Before(*missed)) {
+		missed = firstResponseDueBy
+	}
+
+	return missed
+}
+
 type ITicketRepository interface {
-	FindAll(ctx context.Context, filter FindAllParam) ([]*TicketResponse, error)
+	FindAll(ctx context.Context, filter FindAllParam) (*TicketPage, error)
 	FindById(ctx context.Context, id int64) (*Ticket, error)
 	Create(ctx context.Context, ticket Ticket) (*Ticket, error)
 	Update(ctx context.Context, ticket Ticket) (*Ticket, error)
 	Delete(ctx context.Context, id int64) error
+	// SetFirstResponse records the first response unless one was already
+	// recorded.
+	SetFirstResponse(ctx context.Context, id int64, at time.Time) error
+	// ClaimSLABreaches marks up to limit open tickets whose target is due by
+	// before as warned and returns them. Tickets are claimed once per due
+	// date, so a due date pushed back by a pause is warned about again.
+	ClaimSLABreaches(ctx context.Context, target string, before time.Time, limit int) ([]*Ticket, error)
 }
 
 type ITicketUsecase interface {
-	FindAll(ctx context.Context, filter FindAllParam) ([]*TicketResponse, error)
+	FindAll(ctx context.Context, filter FindAllParam) (*TicketPage, error)
 	FindById(ctx context.Context, id int64) (*TicketResponse, error)
 	Create(ctx context.Context, in CreateTicketInput) (*Ticket, error)
 	Update(ctx context.Context, id int64, in UpdateTicketInput) (*Ticket, error)
 	Delete(ctx context.Context, id int64) error
+	// RecordFirstResponse stops the first-response clock on the first
+	// comment by someone other than the requester.
+	RecordFirstResponse(ctx context.Context, event DomainEvent) error
+	// ScanSLABreaches raises TicketSLAAtRisk for every open ticket whose
+	// first response or resolution is about to fall due, and returns how
+	// many it raised.
+	ScanSLABreaches(ctx context.Context) (int, error)
 }
 
 type Ticket struct {
-	ID          int64      `json:"id"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Status      string     `json:"status"`
-	Priority    string     `json:"priority"`
-	AssignedTo  int64      `json:"assigned_to"`
-	UserID      int64      `json:"user_id"`
-	DueBy       *time.Time `json:"due_by,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	DeletedAt   *time.Time `json:"-"`
+	ID                 int64      `json:"id"`
+	Title              string     `json:"title"`
+	Description        string     `json:"description"`
+	Status             string     `json:"status"`
+	Priority           string     `json:"priority"`
+	AssignedTo         int64      `json:"assigned_to"`
+	UserID             int64      `json:"user_id"`
+	Category           string     `json:"category,omitempty"`
+	Queue              string     `json:"queue,omitempty"`
+	SLAPolicyID        *int64     `json:"sla_policy_id,omitempty"`
+	FirstResponseDueBy *time.Time `json:"first_response_due_by,omitempty"`
+	FirstRespondedAt   *time.Time `json:"first_responded_at,omitempty"`
+	DueBy              *time.Time `json:"due_by,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	DeletedAt          *time.Time `json:"-"`
+	// the due dates the SLA breach scan last warned about
+	FirstResponseWarnedFor *time.Time `json:"-"`
+	DueByWarnedFor         *time.Time `json:"-"`
 }
 
 type TicketResponse struct {
-	ID          int64                          `json:"id"`
-	Title       string                         `json:"title"`
-	Description string                         `json:"description"`
-	Status      string                         `json:"status"`
-	Priority    string                         `json:"priority"`
-	AssignedTo  int64                          `json:"assigned_to"`
-	UserID      int64                          `json:"user_id"`
-	User        *UserResponse                  `json:"user,omitempty"`
-	Comment     []*CommentResponse             `json:"comment,omitempty"`
-	Attachment  []*AttachmentResponseForTicket `json:"attachment,omitempty"`
-	DueBy       *time.Time                     `json:"due_by,omitempty"`
-	CreatedAt   time.Time                      `json:"created_at"`
-	UpdatedAt   time.Time                      `json:"updated_at"`
-	Penalty     bool                           `json:"penalty"`
-	Overdueby   string                         `json:"overdue_by,omitempty"`
+	ID                 int64                          `json:"id"`
+	Title              string                         `json:"title"`
+	Description        string                         `json:"description"`
+	Status             string                         `json:"status"`
+	Priority           string                         `json:"priority"`
+	AssignedTo         int64                          `json:"assigned_to"`
+	UserID             int64                          `json:"user_id"`
+	User               *UserResponse                  `json:"user,omitempty"`
+	Comment            []*CommentResponse             `json:"comment,omitempty"`
+	Attachment         []*AttachmentResponseForTicket `json:"attachment,omitempty"`
+	Category           string                         `json:"category,omitempty"`
+	Queue              string                         `json:"queue,omitempty"`
+	SLAPolicyID        *int64                         `json:"sla_policy_id,omitempty"`
+	FirstResponseDueBy *time.Time                     `json:"first_response_due_by,omitempty"`
+	FirstRespondedAt   *time.Time                     `json:"first_responded_at,omitempty"`
+	DueBy              *time.Time                     `json:"due_by,omitempty"`
+	CreatedAt          time.Time                      `json:"created_at"`
+	UpdatedAt          time.Time                      `json:"updated_at"`
+	Penalty            bool                           `json:"penalty"`
+	Overdueby          string                         `json:"overdue_by,omitempty"`
 }
 
+// Relations that can be embedded in a TicketResponse.
+const (
+	IncludeUser        = "user"
+	IncludeComments    = "comments"
+	IncludeAttachments = "attachments"
+)
+
+// FindAllParam filters the ticket list. UserID is the requester and is
+// forced to the caller for customers. Pages are keyset based: Cursor is the
+// NextCursor of the previous page. A nil Include embeds every relation.
 type FindAllParam struct {
-	Limit int64 `json:"limit"`
-	Page  int64 `json:"page"`
+	Limit       int64      `json:"limit" validate:"min=0,max=100"`
+	Cursor      string     `json:"cursor"`
+	Status      string     `json:"status" validate:"omitempty,oneof=open in_progress pending resolved closed"`
+	Priority    string     `json:"priority" validate:"omitempty,oneof=high medium low very_low"`
+	AssignedTo  int64      `json:"assigned_to"`
+	UserID      int64      `json:"user_id"`
+	CreatedFrom *time.Time `json:"created_from"`
+	CreatedTo   *time.Time `json:"created_to"`
+	DueFrom     *time.Time `json:"due_from"`
+	DueTo       *time.Time `json:"due_to"`
+	OverdueOnly bool       `json:"overdue_only"`
+	Search      string     `json:"search"`
+	SortBy      string     `json:"sort_by" validate:"omitempty,oneof=id created_at updated_at due_by priority"`
+	SortOrder   string     `json:"sort_order" validate:"omitempty,oneof=asc desc"`
+	Include     []string   `json:"-" validate:"omitempty,dive,oneof=user comments attachments"`
+}
+
+// TicketSortColumn returns the sort_by a ticket list is sorted by, which is
+// created_at when none is given.
+func TicketSortColumn(sortBy string) string {
+	if sortBy == "" {
+		return "created_at"
+	}
+	return sortBy
+}
+
+type PageMeta struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int64  `json:"total"`
+	Limit      int64  `json:"limit"`
+}
+
+type TicketPage struct {
+	Tickets []*TicketResponse `json:"tickets"`
+	Meta    PageMeta          `json:"meta"`
 }
 
 type CreateTicketInput struct {
 	Title       string `json:"title" validate:"required"`
 	Description string `json:"description" validate:"required"`
-	Status      string `json:"status" validate:"required"`
+	Status      string `json:"status"`
 	Priority    string `json:"priority" validate:"required"`
 	AssignedTo  int64  `json:"assigned_to" validate:"required"`
+	Category    string `json:"category"`
+	Queue       string `json:"queue"`
 }
 
 type UpdateTicketInput struct {
 	Title       string `json:"title" validate:"required"`
 	Description string `json:"description" validate:"required"`
-	Status      string `json:"status" validate:"required"`
+	Status      string `json:"status" validate:"required,oneof=open in_progress pending resolved closed"`
 	Priority    string `json:"priority" validate:"required"`
 	AssignedTo  int64  `json:"assigned_to" validate:"required"`
+	Category    string `json:"category"`
+	Queue       string `json:"queue"`
 }
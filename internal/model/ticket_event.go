@@ -0,0 +1,37 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// TicketEvent is a domain event as pushed to clients and webhooks. Type is
+// the domain event name. The requester and assignee travel with the event so
+// each client's permissions can be checked without a lookup.
+type TicketEvent struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	TicketID   int64           `json:"ticket_id"`
+	UserID     int64           `json:"user_id"`
+	AssignedTo int64           `json:"assigned_to"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// ITicketEventBroker fans ticket events out to every instance of the app.
+type ITicketEventBroker interface {
+	Publish(ctx context.Context, event TicketEvent) error
+	// Subscribe returns the events published from now on. The channel is
+	// closed when ctx is done or the connection to the broker drops.
+	Subscribe(ctx context.Context) (<-chan TicketEvent, error)
+}
+
+type ITicketEventUsecase interface {
+	// Publish sends the domain event to every instance. It is subscribed to
+	// the event bus after commit.
+	Publish(ctx context.Context, event DomainEvent) error
+	// Subscribe returns the events the caller may see, optionally limited to
+	// one ticket, until ctx is done.
+	Subscribe(ctx context.Context, ticketID int64) (<-chan TicketEvent, error)
+}
@@ -6,20 +6,31 @@ import (
 )
 
 type TicketHistory struct {
-	ID        int64     `json:"id"`
-	TicketID  int64     `json:"ticket_id"`
-	UserID    int64     `json:"user_id"`
-	Status    string    `json:"status"`
-	Priority  string    `json:"priority"`
-	ChangedAt time.Time `json:"changed_at"`
+	ID         int64     `json:"id"`
+	TicketID   int64     `json:"ticket_id"`
+	UserID     int64     `json:"user_id"`
+	FromStatus string    `json:"from_status,omitempty"`
+	Status     string    `json:"status"`
+	Priority   string    `json:"priority"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// PausedInterval is a period a ticket spent in the pending status, during
+// which its SLA clock does not run. To is nil while the ticket is pending.
+type PausedInterval struct {
+	From     time.Time  `json:"from"`
+	To       *time.Time `json:"to,omitempty"`
+	Duration string     `json:"duration,omitempty"`
 }
 
 type ITicketHistoryRepository interface {
 	GetTicketID(ctx context.Context, id int64) (*TicketHistory, error)
+	FindAllByTicketID(ctx context.Context, ticketID int64) ([]TicketHistory, error)
 	GetStatus(ctx context.Context, status string) (*[]TicketHistory, error)
 	GetPriority(ctx context.Context, priority string) (*[]TicketHistory, error)
 	GetUserID(ctx context.Context, userID int64) (*[]TicketHistory, error)
 	Create(ctx context.Context, ticketHistory TicketHistory) error
+	Index(ctx context.Context, histories []TicketHistory) error
 }
 
 type ITicketHistoryUsecase interface {
@@ -27,4 +38,12 @@ type ITicketHistoryUsecase interface {
 	GetStatus(ctx context.Context, status string) (*[]TicketHistory, error)
 	GetPriority(ctx context.Context, priority string) (*[]TicketHistory, error)
 	GetUserID(ctx context.Context, userID int64) (*[]TicketHistory, error)
+	GetPausedIntervals(ctx context.Context, ticketID int64) ([]PausedInterval, error)
+	// Record writes the history row for a created or updated ticket. It is
+	// subscribed to the event bus.
+	Record(ctx context.Context, event DomainEvent) error
+	// Index copies the ticket's history to the search index. It is
+	// subscribed to the event bus after commit, since Postgres stays the
+	// source of truth.
+	Index(ctx context.Context, event DomainEvent) error
 }
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTicketMissedDueDate(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	at := func(hours int) *time.Time {
+		t := now.Add(time.Duration(hours) * time.Hour)
+		return &t
+	}
+
+	tests := []struct {
+		name               string
+		status             string
+		dueBy              *time.Time
+		firstResponseDueBy *time.Time
+		firstRespondedAt   *time.Time
+		want               *time.Time
+	}{
+		{name: "nothing due", status: TicketStatusOpen},
+		{name: "due later", status: TicketStatusOpen, dueBy: at(2), firstResponseDueBy: at(1)},
+		{name: "resolution missed", status: TicketStatusInProgress, dueBy: at(-1), want: at(-1)},
+		{name: "first response missed", status: TicketStatusOpen, dueBy: at(5), firstResponseDueBy: at(-2), want: at(-2)},
+		{name: "first response given", status: TicketStatusOpen, dueBy: at(5), firstResponseDueBy: at(-2), firstRespondedAt: at(-3)},
+		{name: "earliest missed date wins", status: TicketStatusOpen, dueBy: at(-1), firstResponseDueBy: at(-4), want: at(-4)},
+		{name: "pending", status: TicketStatusPending, dueBy: at(-1)},
+		{name: "resolved", status: TicketStatusResolved, dueBy: at(-1), firstResponseDueBy: at(-4)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TicketMissedDueDate(tt.status, tt.dueBy, tt.firstResponseDueBy, tt.firstRespondedAt, now)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil || !got.Equal(*tt.want):
+				t.Errorf("TicketMissedDueDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// TicketTransition allows a ticket to move from one status to another.
+// An empty Roles list lets every role that may update the ticket use it.
+type TicketTransition struct {
+	ID         int64          `json:"id"`
+	FromStatus string         `json:"from_status"`
+	ToStatus   string         `json:"to_status"`
+	Roles      pq.StringArray `json:"roles" gorm:"type:text[]"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+type CreateTicketTransitionInput struct {
+	FromStatus string   `json:"from_status" validate:"required,oneof=open in_progress pending resolved closed"`
+	ToStatus   string   `json:"to_status" validate:"required,oneof=open in_progress pending resolved closed,nefield=FromStatus"`
+	Roles      []string `json:"roles" validate:"dive,oneof=admin support customer"`
+}
+
+type UpdateTicketTransitionInput struct {
+	Roles []string `json:"roles" validate:"dive,oneof=admin support customer"`
+}
+
+type ITicketTransitionRepository interface {
+	FindAll(ctx context.Context) ([]*TicketTransition, error)
+	FindById(ctx context.Context, id int64) (*TicketTransition, error)
+	FindByStatuses(ctx context.Context, from string, to string) (*TicketTransition, error)
+	Create(ctx context.Context, transition TicketTransition) (*TicketTransition, error)
+	Update(ctx context.Context, transition TicketTransition) (*TicketTransition, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type ITicketTransitionUsecase interface {
+	FindAll(ctx context.Context) ([]*TicketTransition, error)
+	Create(ctx context.Context, in CreateTicketTransitionInput) (*TicketTransition, error)
+	Update(ctx context.Context, id int64, in UpdateTicketTransitionInput) (*TicketTransition, error)
+	Delete(ctx context.Context, id int64) error
+	Check(ctx context.Context, from string, to string) error
+}
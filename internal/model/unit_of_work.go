@@ -0,0 +1,20 @@
+package model
+
+import "context"
+
+// IUnitOfWork groups repository writes into one database transaction.
+// Repositories pick the transaction up from the context passed to Do, so
+// they take part without any change to their interfaces.
+type IUnitOfWork interface {
+	// Do runs fn in a transaction that commits when fn returns nil. Calls
+	// nested inside another Do join the outer transaction.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+	// AfterCommit defers side effects outside the database, such as
+	// publishing messages, until the transaction in ctx has committed. They
+	// are dropped on rollback and run at once when ctx has no transaction.
+	AfterCommit(ctx context.Context, fn func(ctx context.Context))
+	// AfterRollback undoes side effects outside the database, such as stored
+	// files, once the transaction in ctx has rolled back. They are dropped
+	// on commit and never run when ctx has no transaction.
+	AfterRollback(ctx context.Context, fn func(ctx context.Context))
+}
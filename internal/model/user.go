@@ -11,31 +11,55 @@ type ContextAuthKey string
 
 const BearerAuthKey ContextAuthKey = "BearerAuth"
 
+const (
+	RoleAdmin    = "admin"
+	RoleSupport  = "support"
+	RoleCustomer = "customer"
+)
+
 type IUserRepository interface {
 	FindAll(ctx context.Context, user User) ([]*User, error)
 	FindById(ctx context.Context, id int64) (*User, error)
+	FindByIDs(ctx context.Context, ids []int64) ([]*User, error)
 	FindByEmail(ctx context.Context, email string) *User
 	Create(ctx context.Context, user User) (*User, error)
 	Update(ctx context.Context, user User) error
 	Delete(ctx context.Context, id int64) error
 	CreateSession(ctx context.Context, session UserSession) (*UserSession, error)
 	FindSessionByToken(ctx context.Context, token string) (*UserSession, error)
+	FindSessionByRefreshToken(ctx context.Context, refreshTokenHash string) (*UserSession, error)
+	RevokeSession(ctx context.Context, session UserSession) (bool, error)
+	RevokeSessionFamily(ctx context.Context, familyID string) error
 	DeleteSession(ctx context.Context, token string) error
+	DeleteSessionsByUserID(ctx context.Context, userID int64) error
+	SaveStreamTicket(ctx context.Context, ticketHash string, accessToken string, ttl time.Duration) error
+	// TakeStreamTicket returns the access token of the ticket and deletes
+	// it, or an empty string when the ticket is unknown or expired.
+	TakeStreamTicket(ctx context.Context, ticketHash string) (string, error)
 }
 
 type IUserUsecase interface {
 	FindAll(ctx context.Context, user User) ([]*User, error)
 	FindById(ctx context.Context, id int64) (*User, error)
-	Create(ctx context.Context, in CreateUserInput) (token string, err error)
+	Create(ctx context.Context, in CreateUserInput) (*TokenPair, error)
 	Update(ctx context.Context, id int64, in UpdateUserInput) error
 	Delete(ctx context.Context, id int64) error
 	ValidateSession(ctx context.Context, token string) (*UserSession, error)
-	Login(ctx context.Context, in LoginInput) (token string, err error)
+	Login(ctx context.Context, in LoginInput) (*TokenPair, error)
+	Refresh(ctx context.Context, in RefreshTokenInput) (*TokenPair, error)
 	Logout(ctx context.Context, token string) error
+	LogoutAll(ctx context.Context, userID int64) error
+	// IssueStreamTicket trades the access token for a short-lived ticket
+	// that authenticates a single event stream request.
+	IssueStreamTicket(ctx context.Context, accessToken string) (*StreamTicket, error)
+	// RedeemStreamTicket returns the access token the ticket was issued
+	// for. A ticket can only be redeemed once.
+	RedeemStreamTicket(ctx context.Context, ticket string) (string, error)
 }
 
 type CustomClaims struct {
-	UserID int64 `json:"user_id"`
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"`
 	jwt.RegisteredClaims
 }
 
@@ -55,12 +79,30 @@ type UserResponse struct {
 	Email string `json:"email"`
 }
 
+// UserSession holds one access/refresh token pair. Sessions rotated from the
+// same login share a FamilyID so the whole chain can be revoked at once.
 type UserSession struct {
-	ID        int64     `json:"id"`
-	UserID    int64     `json:"user_id"`
-	Token     string    `json:"token"`
+	ID               int64      `json:"id"`
+	UserID           int64      `json:"user_id"`
+	FamilyID         string     `json:"family_id"`
+	Token            string     `json:"token"`
+	RefreshTokenHash string     `json:"-"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RefreshExpiresAt time.Time  `json:"refresh_expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// StreamTicket authenticates clients that cannot send an Authorization
+// header, such as EventSource, without putting the access token in a URL.
+type StreamTicket struct {
+	Ticket    string    `json:"ticket"`
 	ExpiresAt time.Time `json:"expires_at"`
-	CreatedAt time.Time `json:"created_at"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 // validation
@@ -69,16 +111,20 @@ type LoginInput struct {
 	Password string `json:"password"`
 }
 
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
 type CreateUserInput struct {
 	Name     string `json:"name" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=3"`
-	Role     string `json:"role" validate:"required"`
+	Role     string `json:"role" validate:"required,oneof=admin support customer"`
 }
 
 type UpdateUserInput struct {
 	Name     string `json:"name" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=3"`
-	Role     string `json:"role" validate:"required"`
+	Role     string `json:"role" validate:"required,oneof=admin support customer"`
 }
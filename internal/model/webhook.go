@@ -0,0 +1,121 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	WebhookDeliveryPending  = "pending"
+	WebhookDeliverySent     = "sent"
+	WebhookDeliveryRetrying = "retrying"
+	WebhookDeliveryFailed   = "failed"
+)
+
+// WebhookSubscription posts the ticket events it lists to URL, signed with
+// Secret. The secret is only returned when the subscription is created or
+// rotated.
+type WebhookSubscription struct {
+	ID        int64          `json:"id"`
+	URL       string         `json:"url"`
+	Events    pq.StringArray `json:"events" gorm:"type:text[]"`
+	Secret    string         `json:"secret,omitempty"`
+	Active    bool           `json:"active"`
+	CreatedBy int64          `json:"created_by"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// WebhookDelivery is one event sent to one subscription. Payload is the exact
+// body that is signed and posted, so a replay sends the same bytes.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// WebhookDeliveryResult is the outcome of one attempt to post a delivery.
+type WebhookDeliveryResult struct {
+	Status         string
+	Attempts       int
+	ResponseStatus int
+	LastError      string
+}
+
+// WebhookDeliveryMessage is the RabbitMQ message that asks the worker to
+// post a delivery.
+type WebhookDeliveryMessage struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+type CreateWebhookSubscriptionInput struct {
+	URL    string   `json:"url" validate:"required,url,max=2048"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=ticket.created ticket.updated ticket.status_changed ticket.assigned ticket.sla_at_risk comment.added attachment.added attachment.infected"`
+	// Secret is generated when left empty.
+	Secret string `json:"secret" validate:"omitempty,min=16,max=255"`
+}
+
+type UpdateWebhookSubscriptionInput struct {
+	URL    string   `json:"url" validate:"required,url,max=2048"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=ticket.created ticket.updated ticket.status_changed ticket.assigned ticket.sla_at_risk comment.added attachment.added attachment.infected"`
+	Active bool     `json:"active"`
+	// RotateSecret replaces the secret with a new generated one.
+	RotateSecret bool `json:"rotate_secret"`
+}
+
+// WebhookDeliveryParam pages through the deliveries of a subscription,
+// newest first.
+type WebhookDeliveryParam struct {
+	Limit  int64  `json:"limit" validate:"min=0,max=100"`
+	Cursor string `json:"cursor"`
+	Status string `json:"status" validate:"omitempty,oneof=pending sent retrying failed"`
+}
+
+type WebhookDeliveryPage struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+	Meta       PageMeta           `json:"meta"`
+}
+
+type IWebhookSubscriptionRepository interface {
+	FindAll(ctx context.Context) ([]*WebhookSubscription, error)
+	FindActiveByEvent(ctx context.Context, eventType string) ([]*WebhookSubscription, error)
+	FindById(ctx context.Context, id int64) (*WebhookSubscription, error)
+	Create(ctx context.Context, subscription WebhookSubscription) (*WebhookSubscription, error)
+	Update(ctx context.Context, subscription WebhookSubscription) (*WebhookSubscription, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type IWebhookDeliveryRepository interface {
+	FindById(ctx context.Context, id int64) (*WebhookDelivery, error)
+	FindAllBySubscriptionID(ctx context.Context, subscriptionID int64, param WebhookDeliveryParam) (*WebhookDeliveryPage, error)
+	Create(ctx context.Context, delivery *WebhookDelivery) error
+	UpdateResult(ctx context.Context, id int64, result WebhookDeliveryResult) error
+}
+
+type IWebhookUsecase interface {
+	FindSubscriptions(ctx context.Context) ([]*WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, in CreateWebhookSubscriptionInput) (*WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, id int64, in UpdateWebhookSubscriptionInput) (*WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+	FindDeliveries(ctx context.Context, subscriptionID int64, param WebhookDeliveryParam) (*WebhookDeliveryPage, error)
+	ReplayDelivery(ctx context.Context, id int64) (*WebhookDelivery, error)
+	// Dispatch records a delivery for every active subscription to the
+	// event and queues them, in the transaction in ctx. It is subscribed to
+	// the event bus.
+	Dispatch(ctx context.Context, event DomainEvent) error
+	// Deliver posts a queued delivery and records the outcome. attempt
+	// counts from 1; a failure on the last attempt marks it failed.
+	Deliver(ctx context.Context, id int64, attempt int) (*WebhookDelivery, error)
+}
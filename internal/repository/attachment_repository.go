@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"helpdesk-ticketing-system/internal/model"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -15,6 +16,17 @@ func NewAttachmentRepo(db *gorm.DB) model.IAttachmentRepository {
 	return &AttachmentRepo{db: db}
 }
 
+func (a *AttachmentRepo) FindById(ctx context.Context, id int64) (*model.Attachment, error) {
+	var attachment model.Attachment
+
+	err := a.db.WithContext(ctx).First(&attachment, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
 func (a *AttachmentRepo) FindAllByTicketID(ctx context.Context, ticketID int64) ([]*model.Attachment, error) {
 	var attachments []*model.Attachment
 	query := a.db.WithContext(ctx).Model(&model.Attachment{})
@@ -24,11 +36,52 @@ func (a *AttachmentRepo) FindAllByTicketID(ctx context.Context, ticketID int64)
 	return attachments, err
 }
 
-func (a *AttachmentRepo) Create(ctx context.Context, attachment model.Attachment) error {
-	err := a.db.WithContext(ctx).Create(&attachment).Error
+func (a *AttachmentRepo) FindAllByTicketIDs(ctx context.Context, ticketIDs []int64) ([]*model.Attachment, error) {
+	var attachments []*model.Attachment
+	if len(ticketIDs) == 0 {
+		return attachments, nil
+	}
+
+	err := a.db.WithContext(ctx).Where("ticket_id IN ?", ticketIDs).Order("uploaded_at ASC").Find(&attachments).Error
+
+	return attachments, err
+}
+
+func (a *AttachmentRepo) Create(ctx context.Context, attachment *model.Attachment) error {
+	err := conn(ctx, a.db).Create(attachment).Error
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func (a *AttachmentRepo) FindQuarantined(ctx context.Context, uploadedBefore time.Time, limit int) ([]*model.Attachment, error) {
+	var attachments []*model.Attachment
+
+	err := a.db.WithContext(ctx).
+		Where("scan_status = ? AND uploaded_at < ?", model.AttachmentScanQuarantined, uploadedBefore).
+		Order("uploaded_at ASC").
+		Limit(limit).
+		Find(&attachments).Error
+
+	return attachments, err
+}
+
+// UpdateScanResult only updates quarantined rows, so when two workers scan
+// the same attachment only the first result is kept.
+func (a *AttachmentRepo) UpdateScanResult(ctx context.Context, attachment *model.Attachment) (bool, error) {
+	result := conn(ctx, a.db).
+		Model(&model.Attachment{}).
+		Where("id = ? AND scan_status = ?", attachment.ID, model.AttachmentScanQuarantined).
+		Updates(map[string]interface{}{
+			"scan_status":    attachment.ScanStatus,
+			"scan_signature": attachment.ScanSignature,
+			"scanned_at":     attachment.ScannedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"helpdesk-ticketing-system/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BusinessCalendarRepo struct {
+	db *gorm.DB
+}
+
+func NewBusinessCalendarRepo(db *gorm.DB) model.IBusinessCalendarRepository {
+	return &BusinessCalendarRepo{db: db}
+}
+
+func (b *BusinessCalendarRepo) FindAll(ctx context.Context) ([]*model.BusinessCalendar, error) {
+	var calendars []*model.BusinessCalendar
+
+	err := b.db.WithContext(ctx).
+		Preload("Hours").
+		Preload("Holidays").
+		Where("deleted_at IS NULL").
+		Order("id ASC").
+		Find(&calendars).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return calendars, nil
+}
+
+func (b *BusinessCalendarRepo) FindById(ctx context.Context, id int64) (*model.BusinessCalendar, error) {
+	var calendar model.BusinessCalendar
+
+	err := b.db.WithContext(ctx).
+		Preload("Hours").
+		Preload("Holidays").
+		Where("deleted_at IS NULL").
+		First(&calendar, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &calendar, nil
+}
+
+// FindDefault returns nil without an error when no default calendar is set,
+// in which case SLA time runs around the clock.
+func (b *BusinessCalendarRepo) FindDefault(ctx context.Context) (*model.BusinessCalendar, error) {
+	var calendar model.BusinessCalendar
+
+	err := b.db.WithContext(ctx).
+		Preload("Hours").
+		Preload("Holidays").
+		Where("deleted_at IS NULL AND is_default = ?", true).
+		First(&calendar).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &calendar, nil
+}
+
+func (b *BusinessCalendarRepo) Create(ctx context.Context, calendar model.BusinessCalendar) (*model.BusinessCalendar, error) {
+	err := b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if calendar.IsDefault {
+			err := tx.Model(&model.BusinessCalendar{}).
+				Where("is_default = ?", true).
+				Update("is_default", false).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&calendar).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &calendar, nil
+}
+
+// Update replaces the working hours and holidays of the calendar as a whole.
+func (b *BusinessCalendarRepo) Update(ctx context.Context, calendar model.BusinessCalendar) (*model.BusinessCalendar, error) {
+	err := b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if calendar.IsDefault {
+			err := tx.Model(&model.BusinessCalendar{}).
+				Where("is_default = ? AND id <> ?", true, calendar.ID).
+				Update("is_default", false).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err := tx.Model(&model.BusinessCalendar{}).
+			Where("id = ?", calendar.ID).
+			Updates(map[string]interface{}{
+				"name":       calendar.Name,
+				"timezone":   calendar.Timezone,
+				"is_default": calendar.IsDefault,
+				"updated_at": calendar.UpdatedAt,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("business_calendar_id = ?", calendar.ID).Delete(&model.BusinessHour{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("business_calendar_id = ?", calendar.ID).Delete(&model.BusinessHoliday{}).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range calendar.Hours {
+			calendar.Hours[i].BusinessCalendarID = calendar.ID
+		}
+
+		err = tx.Create(&calendar.Hours).Error
+		if err != nil {
+			return err
+		}
+
+		if len(calendar.Holidays) == 0 {
+			return nil
+		}
+
+		for i := range calendar.Holidays {
+			calendar.Holidays[i].BusinessCalendarID = calendar.ID
+		}
+
+		return tx.Create(&calendar.Holidays).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &calendar, nil
+}
+
+func (b *BusinessCalendarRepo) Delete(ctx context.Context, id int64) error {
+	err := b.db.WithContext(ctx).
+		Model(&model.BusinessCalendar{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"deleted_at": time.Now(),
+			"is_default": false,
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -49,8 +49,21 @@ func (c *CommentRepo) FindAllByTicketID(ctx context.Context, ticketID int64) ([]
 	return comments, nil
 }
 
+func (c *CommentRepo) FindAllByTicketIDs(ctx context.Context, ticketIDs []int64) ([]*model.Comment, error) {
+	var comments []*model.Comment
+	if len(ticketIDs) == 0 {
+		return comments, nil
+	}
+
+	err := c.db.WithContext(ctx).Where("ticket_id IN ?", ticketIDs).Order("created_at ASC").Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
 func (c *CommentRepo) Create(ctx context.Context, comment model.Comment) (*model.Comment, error) {
-	err := c.db.WithContext(ctx).Create(&comment).Error
+	err := conn(ctx, c.db).Create(&comment).Error
 	if err != nil {
 		return nil, err
 	}
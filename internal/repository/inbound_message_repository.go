@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"helpdesk-ticketing-system/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type InboundMessageRepo struct {
+	db *gorm.DB
+}
+
+func NewInboundMessageRepo(db *gorm.DB) model.IInboundMessageRepository {
+	return &InboundMessageRepo{db: db}
+}
+
+// FindByMessageID returns nil without an error for messages not seen before.
+func (i *InboundMessageRepo) FindByMessageID(ctx context.Context, messageID string) (*model.InboundMessage, error) {
+	var message model.InboundMessage
+
+	err := conn(ctx, i.db).Where("message_id = ?", messageID).First(&message).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+// FindTicketIDByMessageIDs returns the ticket of the most recent known message
+// among messageIDs, or 0 when none of them is known.
+func (i *InboundMessageRepo) FindTicketIDByMessageIDs(ctx context.Context, messageIDs []string) (int64, error) {
+	if len(messageIDs) == 0 {
+		return 0, nil
+	}
+
+	var messages []model.InboundMessage
+
+	err := conn(ctx, i.db).
+		Where("message_id IN ?", messageIDs).
+		Order("id DESC").
+		Limit(1).
+		Find(&messages).Error
+	if err != nil {
+		return 0, err
+	}
+
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	return messages[0].TicketID, nil
+}
+
+func (i *InboundMessageRepo) Create(ctx context.Context, message *model.InboundMessage) error {
+	err := conn(ctx, i.db).Create(message).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"helpdesk-ticketing-system/internal/model"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize stays well below clamd's default StreamMaxLength.
+const clamdChunkSize = 64 << 10
+
+// ClamdScanner streams files to a clamd daemon with the INSTREAM command.
+type ClamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner connects to address, either host:port, tcp://host:port,
+// unix:///path/to/clamd.ctl or an absolute socket path.
+func NewClamdScanner(address string, timeout time.Duration) model.IMalwareScanner {
+	network := "tcp"
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		network, address = "unix", strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "/"):
+		network = "unix"
+	default:
+		address = strings.TrimPrefix(address, "tcp://")
+	}
+
+	return &ClamdScanner{
+		network: network,
+		address: address,
+		timeout: timeout,
+	}
+}
+
+func (c *ClamdScanner) Scan(ctx context.Context, r io.Reader) (*model.ScanResult, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.stream(conn, r)
+	if err != nil {
+		// clamd closes the connection early when the stream is over its
+		// limit, and says why before it does
+		reply, replyErr := readClamdReply(conn)
+		if replyErr == nil && reply != "" {
+			return parseClamdReply(reply)
+		}
+		return nil, err
+	}
+
+	reply, err := readClamdReply(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+
+	return parseClamdReply(reply)
+}
+
+// stream sends r as length-prefixed chunks, ended by a zero length.
+func (c *ClamdScanner) stream(conn net.Conn, r io.Reader) error {
+	_, err := conn.Write([]byte("zINSTREAM\x00"))
+	if err != nil {
+		return err
+	}
+
+	chunk := make([]byte, 4+clamdChunkSize)
+	for {
+		n, readErr := r.Read(chunk[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(chunk[:4], uint32(n))
+			_, err = conn.Write(chunk[:4+n])
+			if err != nil {
+				return err
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+
+	_, err = conn.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+func readClamdReply(conn net.Conn) (string, error) {
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(errors.Is(err, io.EOF) && reply != "") {
+		return "", err
+	}
+
+	return strings.TrimSpace(strings.TrimRight(reply, "\x00")), nil
+}
+
+// parseClamdReply reads replies such as "stream: OK" and
+// "stream: Win.Test.EICAR_HDB-1 FOUND".
+func parseClamdReply(reply string) (*model.ScanResult, error) {
+	result := strings.TrimPrefix(reply, "stream: ")
+
+	switch {
+	case result == "OK":
+		return &model.ScanResult{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return &model.ScanResult{
+			Infected:  true,
+			Signature: strings.TrimSuffix(result, " FOUND"),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("clamd: %s", reply)
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"helpdesk-ticketing-system/internal/model"
+	"reflect"
+	"testing"
+)
+
+func TestParseClamdReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		want    *model.ScanResult
+		wantErr bool
+	}{
+		{name: "clean", reply: "stream: OK", want: &model.ScanResult{}},
+		{
+			name:  "infected",
+			reply: "stream: Win.Test.EICAR_HDB-1 FOUND",
+			want:  &model.ScanResult{Infected: true, Signature: "Win.Test.EICAR_HDB-1"},
+		},
+		{
+			name:  "signature with spaces",
+			reply: "stream: Heuristics.Phishing Email.SpoofedDomain FOUND",
+			want:  &model.ScanResult{Infected: true, Signature: "Heuristics.Phishing Email.SpoofedDomain"},
+		},
+		{name: "size limit", reply: "INSTREAM size limit exceeded. ERROR", wantErr: true},
+		{name: "scan error", reply: "stream: Can't allocate memory ERROR", wantErr: true},
+		{name: "empty", reply: "", wantErr: true},
+		{name: "found without signature", reply: "stream: FOUND", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseClamdReply(tt.reply)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseClamdReply(%q) = %+v, want error", tt.reply, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseClamdReply(%q) error = %v", tt.reply, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseClamdReply(%q) = %+v, want %+v", tt.reply, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"helpdesk-ticketing-system/internal/model"
+	"io"
+)
+
+// eicarMarker is part of the EICAR anti-virus test file, which every scanner
+// reports as infected.
+var eicarMarker = []byte("EICAR-STANDARD-ANTIVIRUS-TEST-FILE")
+
+// FakeScanner stands in for clamd in development and tests. It reports files
+// containing the EICAR test string as infected and every other file as
+// clean.
+type FakeScanner struct{}
+
+func NewFakeScanner() model.IMalwareScanner {
+	return &FakeScanner{}
+}
+
+func (f *FakeScanner) Scan(ctx context.Context, r io.Reader) (*model.ScanResult, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Contains(content, eicarMarker) {
+		return &model.ScanResult{Infected: true, Signature: "Eicar-Test-Signature"}, nil
+	}
+
+	return &model.ScanResult{}, nil
+}
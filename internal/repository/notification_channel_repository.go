@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"helpdesk-ticketing-system/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type NotificationChannelRepo struct {
+	db *gorm.DB
+}
+
+func NewNotificationChannelRepo(db *gorm.DB) model.INotificationChannelRepository {
+	return &NotificationChannelRepo{db: db}
+}
+
+func (n *NotificationChannelRepo) FindAllByUserID(ctx context.Context, userID int64) ([]*model.NotificationChannelSetting, error) {
+	var settings []*model.NotificationChannelSetting
+
+	err := conn(ctx, n.db).
+		Where("user_id = ?", userID).
+		Order("id ASC").
+		Find(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (n *NotificationChannelRepo) FindById(ctx context.Context, id int64) (*model.NotificationChannelSetting, error) {
+	var setting model.NotificationChannelSetting
+
+	err := conn(ctx, n.db).First(&setting, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &setting, nil
+}
+
+func (n *NotificationChannelRepo) Create(ctx context.Context, setting model.NotificationChannelSetting) (*model.NotificationChannelSetting, error) {
+	err := conn(ctx, n.db).Create(&setting).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &setting, nil
+}
+
+func (n *NotificationChannelRepo) Delete(ctx context.Context, id int64) error {
+	err := conn(ctx, n.db).Delete(&model.NotificationChannelSetting{}, id).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"helpdesk-ticketing-system/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationPreferenceRepo struct {
+	db *gorm.DB
+}
+
+func NewNotificationPreferenceRepo(db *gorm.DB) model.INotificationPreferenceRepository {
+	return &NotificationPreferenceRepo{db: db}
+}
+
+// FindSettings returns nil without an error for users who never saved any.
+func (n *NotificationPreferenceRepo) FindSettings(ctx context.Context, userID int64) (*model.NotificationSettings, error) {
+	var settings model.NotificationSettings
+
+	err := conn(ctx, n.db).Where("user_id = ?", userID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+func (n *NotificationPreferenceRepo) FindAllByUserID(ctx context.Context, userID int64) ([]*model.NotificationPreference, error) {
+	var preferences []*model.NotificationPreference
+
+	err := conn(ctx, n.db).
+		Where("user_id = ?", userID).
+		Order("event ASC, channel ASC").
+		Find(&preferences).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// Save upserts the settings and replaces the preference rows as a whole.
+func (n *NotificationPreferenceRepo) Save(ctx context.Context, settings model.NotificationSettings, preferences []model.NotificationPreference) error {
+	return conn(ctx, n.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"timezone", "quiet_hours_start", "quiet_hours_end", "digest_mode", "digest_hour", "updated_at"}),
+		}).Create(&settings).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("user_id = ?", settings.UserID).Delete(&model.NotificationPreference{}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(preferences) == 0 {
+			return nil
+		}
+
+		return tx.Create(&preferences).Error
+	})
+}
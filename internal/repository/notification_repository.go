@@ -2,11 +2,16 @@ package repository
 
 import (
 	"context"
+	"helpdesk-ticketing-system/internal/helper"
 	"helpdesk-ticketing-system/internal/model"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+const defaultNotificationLimit = 20
+
 type NotificationRepo struct {
 	db *gorm.DB
 }
@@ -16,10 +21,192 @@ func NewNotificationRepo(db *gorm.DB) model.INotificationRepository {
 }
 
 func (n *NotificationRepo) Save(ctx context.Context, notification *model.Notification) error {
-	err := n.db.WithContext(ctx).Create(notification).Error
+	err := conn(ctx, n.db).Create(notification).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (n *NotificationRepo) UpdateDelivery(ctx context.Context, id int64, delivery model.NotificationDelivery) error {
+	updates := map[string]interface{}{
+		"status":     delivery.Status,
+		"attempts":   delivery.Attempts,
+		"last_error": delivery.LastError,
+		"updated_at": time.Now(),
+	}
+	if delivery.Status == model.NotificationStatusSent {
+		updates["sent_at"] = time.Now()
+	}
+
+	err := conn(ctx, n.db).
+		Model(&model.Notification{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindHeldDue returns the held notifications whose quiet hours are over,
+// ordered so that each recipient's rows are adjacent. The rows are not
+// locked; LockHeld claims them before they are digested.
+func (n *NotificationRepo) FindHeldDue(ctx context.Context, now time.Time) ([]*model.Notification, error) {
+	var notifications []*model.Notification
+
+	err := conn(ctx, n.db).
+		Where("status = ? AND held_until <= ?", model.NotificationStatusHeld, now).
+		Order("user_id ASC, channel ASC, target ASC, id ASC").
+		Find(&notifications).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (n *NotificationRepo) LockHeld(ctx context.Context, ids []int64) ([]*model.Notification, error) {
+	var notifications []*model.Notification
+
+	err := conn(ctx, n.db).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("id IN ? AND status = ?", ids, model.NotificationStatusHeld).
+		Order("id ASC").
+		Find(&notifications).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// MarkDigested records the digest the notifications were sent in.
+func (n *NotificationRepo) MarkDigested(ctx context.Context, ids []int64, digestID int64) error {
+	err := conn(ctx, n.db).
+		Model(&model.Notification{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"status":      model.NotificationStatusDigested,
+			"digest_id":   digestID,
+			"digested_at": time.Now(),
+			"updated_at":  time.Now(),
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (n *NotificationRepo) FindById(ctx context.Context, id int64) (*model.Notification, error) {
+	var notification model.Notification
+
+	err := conn(ctx, n.db).Where("id = ?", id).First(&notification).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &notification, nil
+}
+
+// FindInbox returns a page of the user's in-app notifications, newest first,
+// with the number of unread ones.
+func (n *NotificationRepo) FindInbox(ctx context.Context, userID int64, param model.NotificationInboxParam) (*model.NotificationPage, error) {
+	if param.Limit <= 0 {
+		param.Limit = defaultNotificationLimit
+	}
+
+	inbox := func() *gorm.DB {
+		return conn(ctx, n.db).
+			Model(&model.Notification{}).
+			Where("user_id = ? AND channel = ?", userID, model.NotificationChannelInApp)
+	}
+
+	var unread int64
+	err := inbox().Where("read_at IS NULL").Count(&unread).Error
+	if err != nil {
+		return nil, err
+	}
+
+	query := inbox()
+	if param.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	err = query.Count(&total).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if param.Cursor != "" {
+		_, id, err := helper.DecodeCursor(param.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("id < ?", id)
+	}
+
+	var notifications []*model.Notification
+	err = query.
+		Order("id DESC").
+		Limit(int(param.Limit) + 1).
+		Find(&notifications).Error
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.NotificationPage{
+		Notifications: notifications,
+		Meta: model.NotificationPageMeta{
+			PageMeta: model.PageMeta{
+				Total: total,
+				Limit: param.Limit,
+			},
+			Unread: unread,
+		},
+	}
+
+	if int64(len(notifications)) > param.Limit {
+		page.Notifications = notifications[:param.Limit]
+		last := page.Notifications[len(page.Notifications)-1]
+		page.Meta.NextCursor = helper.EncodeCursor("", last.ID)
+	}
+
+	return page, nil
+}
+
+func (n *NotificationRepo) MarkRead(ctx context.Context, id int64) error {
+	err := conn(ctx, n.db).
+		Model(&model.Notification{}).
+		Where("id = ? AND read_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"read_at":    time.Now(),
+			"updated_at": time.Now(),
+		}).Error
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// MarkAllRead marks every unread in-app notification of the user as read and
+// returns how many there were.
+func (n *NotificationRepo) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	result := conn(ctx, n.db).
+		Model(&model.Notification{}).
+		Where("user_id = ? AND channel = ? AND read_at IS NULL", userID, model.NotificationChannelInApp).
+		Updates(map[string]interface{}{
+			"read_at":    time.Now(),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
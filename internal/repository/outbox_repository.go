@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"helpdesk-ticketing-system/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepo struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepo(db *gorm.DB) model.IOutboxRepository {
+	return &OutboxRepo{db: db}
+}
+
+func (o *OutboxRepo) Create(ctx context.Context, message model.OutboxMessage) (*model.OutboxMessage, error) {
+	err := conn(ctx, o.db).Create(&message).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+func (o *OutboxRepo) FindPending(ctx context.Context, limit int) ([]*model.OutboxMessage, error) {
+	var messages []*model.OutboxMessage
+
+	err := conn(ctx, o.db).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND available_at <= ?", model.OutboxStatusPending, time.Now()).
+		Order("id ASC").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (o *OutboxRepo) MarkDispatched(ctx context.Context, id int64) error {
+	err := conn(ctx, o.db).
+		Model(&model.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":        model.OutboxStatusDispatched,
+			"attempts":      gorm.Expr("attempts + 1"),
+			"last_error":    "",
+			"dispatched_at": time.Now(),
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *OutboxRepo) MarkRetry(ctx context.Context, message model.OutboxMessage) error {
+	err := conn(ctx, o.db).
+		Model(&model.OutboxMessage{}).
+		Where("id = ?", message.ID).
+		Updates(map[string]interface{}{
+			"status":       message.Status,
+			"attempts":     message.Attempts,
+			"last_error":   message.LastError,
+			"available_at": message.AvailableAt,
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
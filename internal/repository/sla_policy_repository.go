@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"helpdesk-ticketing-system/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SLAPolicyRepo struct {
+	db *gorm.DB
+}
+
+func NewSLAPolicyRepo(db *gorm.DB) model.ISLAPolicyRepository {
+	return &SLAPolicyRepo{db: db}
+}
+
+func (s *SLAPolicyRepo) FindAll(ctx context.Context) ([]*model.SLAPolicy, error) {
+	var policies []*model.SLAPolicy
+
+	err := s.db.WithContext(ctx).
+		Preload("Targets").
+		Where("deleted_at IS NULL").
+		Order("id ASC").
+		Find(&policies).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func (s *SLAPolicyRepo) FindById(ctx context.Context, id int64) (*model.SLAPolicy, error) {
+	var policy model.SLAPolicy
+
+	err := s.db.WithContext(ctx).
+		Preload("Targets").
+		Where("deleted_at IS NULL").
+		First(&policy, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func (s *SLAPolicyRepo) Create(ctx context.Context, policy model.SLAPolicy) (*model.SLAPolicy, error) {
+	err := s.db.WithContext(ctx).Create(&policy).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// Update replaces the policy targets as a whole, so priorities left out of
+// the request fall back to the built-in defaults.
+func (s *SLAPolicyRepo) Update(ctx context.Context, policy model.SLAPolicy) (*model.SLAPolicy, error) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.SLAPolicy{}).
+			Where("id = ?", policy.ID).
+			Updates(map[string]interface{}{
+				"name":                 policy.Name,
+				"customer_id":          policy.CustomerID,
+				"category":             policy.Category,
+				"queue":                policy.Queue,
+				"business_calendar_id": policy.BusinessCalendarID,
+				"updated_at":           policy.UpdatedAt,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("sla_policy_id = ?", policy.ID).Delete(&model.SLATarget{}).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range policy.Targets {
+			policy.Targets[i].SLAPolicyID = policy.ID
+		}
+
+		return tx.Create(&policy.Targets).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func (s *SLAPolicyRepo) Delete(ctx context.Context, id int64) error {
+	err := s.db.WithContext(ctx).
+		Model(&model.SLAPolicy{}).
+		Where("id = ?", id).
+		Update("deleted_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
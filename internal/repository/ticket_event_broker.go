@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"helpdesk-ticketing-system/internal/model"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const ticketEventChannel = "tickets:events"
+
+type TicketEventBroker struct {
+	rdb *redis.Client
+}
+
+func NewTicketEventBroker(rdb *redis.Client) model.ITicketEventBroker {
+	return &TicketEventBroker{rdb: rdb}
+}
+
+func (t *TicketEventBroker) Publish(ctx context.Context, event model.TicketEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return t.rdb.Publish(ctx, ticketEventChannel, data).Err()
+}
+
+func (t *TicketEventBroker) Subscribe(ctx context.Context) (<-chan model.TicketEvent, error) {
+	pubsub := t.rdb.Subscribe(ctx, ticketEventChannel)
+
+	// wait for the subscription to be confirmed so no event is missed
+	_, err := pubsub.Receive(ctx)
+	if err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	events := make(chan model.TicketEvent)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var event model.TicketEvent
+				err := json.Unmarshal([]byte(message.Payload), &event)
+				if err != nil {
+					log.Println("Failed to decode ticket event:", err)
+					continue
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
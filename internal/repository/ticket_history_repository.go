@@ -22,16 +22,30 @@ func NewTicketHistoryRepo(db *gorm.DB, esClient *elastic.Client) model.ITicketHi
 	}
 }
 
-func (t *TicketHistoryRepo) IndexToElasticsearch(history *model.TicketHistory) error {
-	ctx := context.Background()
+// Index writes the histories to the search index in one bulk request.
+// Documents are keyed by row ID, so indexing a row again replaces it.
+func (t *TicketHistoryRepo) Index(ctx context.Context, histories []model.TicketHistory) error {
+	if len(histories) == 0 {
+		return nil
+	}
 
-	_, err := t.esClient.Index().
-		Index("ticket_history").
-		Id(fmt.Sprintf("%d", history.ID)).
-		BodyJson(history).
-		Do(ctx)
+	bulk := t.esClient.Bulk().Index("ticket_history")
+	for _, history := range histories {
+		bulk.Add(elastic.NewBulkIndexRequest().
+			Id(fmt.Sprintf("%d", history.ID)).
+			Doc(history))
+	}
+
+	resp, err := bulk.Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if failed := resp.Failed(); len(failed) > 0 {
+		return fmt.Errorf("failed to index %d ticket histories: %s", len(failed), failed[0].Error.Reason)
+	}
 
-	return err
+	return nil
 }
 
 func (t *TicketHistoryRepo) GetTicketID(ctx context.Context, id int64) (*model.TicketHistory, error) {
@@ -48,6 +62,20 @@ func (t *TicketHistoryRepo) GetTicketID(ctx context.Context, id int64) (*model.T
 	return &history, err
 }
 
+func (t *TicketHistoryRepo) FindAllByTicketID(ctx context.Context, ticketID int64) ([]model.TicketHistory, error) {
+	var histories []model.TicketHistory
+
+	err := conn(ctx, t.db).
+		Where("ticket_id = ?", ticketID).
+		Order("changed_at ASC, id ASC").
+		Find(&histories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
+
 func (t *TicketHistoryRepo) GetStatus(ctx context.Context, status string) (*[]model.TicketHistory, error) {
 	var histories []model.TicketHistory
 
@@ -130,19 +158,10 @@ func (t *TicketHistoryRepo) GetUserID(ctx context.Context, userID int64) (*[]mod
 }
 
 func (t *TicketHistoryRepo) Create(ctx context.Context, ticketHistory model.TicketHistory) error {
-	err := t.db.WithContext(ctx).Create(&ticketHistory).Error
+	err := conn(ctx, t.db).Create(&ticketHistory).Error
 	if err != nil {
 		return err
 	}
 
-	_, err = t.esClient.Index().
-		Index("ticket_history").
-		Id(fmt.Sprintf("%d", ticketHistory.ID)).
-		BodyJson(ticketHistory).
-		Do(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to index ticket history to Elasticsearch: %w", err)
-	}
-
 	return nil
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"helpdesk-ticketing-system/internal/helper"
 	"helpdesk-ticketing-system/internal/model"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -12,10 +14,31 @@ import (
 )
 
 const (
-	cacheKeyAll  = "tickets:all"
-	cacheKeyByID = "ticket:%d"
+	cacheKeyList        = "tickets:list:%d:%s"
+	cacheKeyListVersion = "tickets:list:version"
+	cacheKeyByID        = "ticket:%d"
+
+	defaultTicketLimit = 20
+	cursorTimeFormat   = "2006-01-02 15:04:05.999999"
 )
 
+// ticketSortColumns maps the sort_by values to SQL. Tickets without a due
+// date sort after every dated one.
+var ticketSortColumns = map[string]string{
+	"id":         "id",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"due_by":     "COALESCE(due_by, 'infinity'::timestamp)",
+	"priority":   "priority",
+}
+
+var ticketSortTypes = map[string]string{
+	"created_at": "timestamp",
+	"updated_at": "timestamp",
+	"due_by":     "timestamp",
+	"priority":   "priority",
+}
+
 type TaskRepo struct {
 	db  *gorm.DB
 	rdb *redis.Client
@@ -28,37 +51,167 @@ func NewTicketRepo(db *gorm.DB, rdb *redis.Client) model.ITicketRepository {
 	}
 }
 
-func (t *TaskRepo) FindAll(ctx context.Context, filter model.FindAllParam) ([]*model.TicketResponse, error) {
-	cached, err := t.rdb.Get(ctx, cacheKeyAll).Result()
+func (t *TaskRepo) FindAll(ctx context.Context, filter model.FindAllParam) (*model.TicketPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultTicketLimit
+	}
+
+	cacheKey := t.listCacheKey(ctx, filter)
+
+	cached, err := t.rdb.Get(ctx, cacheKey).Result()
 	if err == nil {
-		var tickets []*model.TicketResponse
-		if err := json.Unmarshal([]byte(cached), &tickets); err == nil {
-			return tickets, nil
+		var page model.TicketPage
+		if err := json.Unmarshal([]byte(cached), &page); err == nil {
+			return &page, nil
 		}
 	}
 
-	var tickets []*model.TicketResponse
-	query := t.db.WithContext(ctx).Model(&model.Ticket{})
+	filter.SortBy = model.TicketSortColumn(filter.SortBy)
+	sortColumn, ok := ticketSortColumns[filter.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort column %q", filter.SortBy)
+	}
+
+	direction, comparator := "DESC", "<"
+	if filter.SortOrder == "asc" {
+		direction, comparator = "ASC", ">"
+	}
 
-	if filter.Limit > 0 {
-		query = query.Limit(int(filter.Limit))
+	query := applyTicketFilter(t.db.WithContext(ctx).Model(&model.Ticket{}), filter)
+
+	var total int64
+	err = query.Count(&total).Error
+	if err != nil {
+		return nil, err
 	}
-	if filter.Page > 0 {
-		offset := int((filter.Page - 1) * filter.Limit)
-		query = query.Offset(offset)
+
+	if filter.Cursor != "" {
+		_, value, id, err := helper.DecodeSortCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if filter.SortBy == "id" {
+			query = query.Where(fmt.Sprintf("id %s ?", comparator), id)
+		} else {
+			query = query.Where(fmt.Sprintf("(%s, id) %s (CAST(? AS %s), ?)", sortColumn, comparator, ticketSortTypes[filter.SortBy]), value, id)
+		}
 	}
 
-	err = query.Where("deleted_at IS NULL").Find(&tickets).Error
+	var tickets []*model.TicketResponse
+	err = query.
+		Order(fmt.Sprintf("%s %s, id %s", sortColumn, direction, direction)).
+		Limit(int(filter.Limit) + 1).
+		Find(&tickets).Error
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := json.Marshal(tickets)
+	page := &model.TicketPage{
+		Tickets: tickets,
+		Meta: model.PageMeta{
+			Total: total,
+			Limit: filter.Limit,
+		},
+	}
+
+	if int64(len(tickets)) > filter.Limit {
+		page.Tickets = tickets[:filter.Limit]
+		last := page.Tickets[len(page.Tickets)-1]
+		page.Meta.NextCursor = helper.EncodeSortCursor(filter.SortBy, ticketSortValue(last, filter.SortBy), last.ID)
+	}
+
+	data, err := json.Marshal(page)
 	if err == nil {
-		t.rdb.Set(ctx, cacheKeyAll, data, time.Minute*5)
+		t.rdb.Set(ctx, cacheKey, data, time.Minute*5)
 	}
 
-	return tickets, nil
+	return page, nil
+}
+
+func applyTicketFilter(query *gorm.DB, filter model.FindAllParam) *gorm.DB {
+	query = query.Where("deleted_at IS NULL")
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Priority != "" {
+		query = query.Where("priority = ?", filter.Priority)
+	}
+	if filter.AssignedTo > 0 {
+		query = query.Where("assigned_to = ?", filter.AssignedTo)
+	}
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at <= ?", *filter.CreatedTo)
+	}
+	if filter.DueFrom != nil {
+		query = query.Where("due_by >= ?", *filter.DueFrom)
+	}
+	if filter.DueTo != nil {
+		query = query.Where("due_by <= ?", *filter.DueTo)
+	}
+	if filter.OverdueOnly {
+		// due dates are pushed back by the business time spent pending when
+		// a ticket leaves the pending status, and pending tickets are left
+		// out while their clock is stopped, so the stored columns are the
+		// pause-adjusted ones
+		now := time.Now()
+		query = query.Where(model.TicketOverdueCondition, now, now)
+	}
+	if filter.Search != "" {
+		query = query.Where(`title ILIKE ? ESCAPE '\'`, "%"+escapeLike(filter.Search)+"%")
+	}
+
+	return query
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes the LIKE wildcards in s so it is matched literally.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// ticketSortValue renders the sort column of a row the way it is compared
+// in the keyset condition.
+func ticketSortValue(ticket *model.TicketResponse, sortBy string) string {
+	switch sortBy {
+	case "updated_at":
+		return ticket.UpdatedAt.Format(cursorTimeFormat)
+	case "due_by":
+		if ticket.DueBy == nil {
+			return "infinity"
+		}
+		return ticket.DueBy.Format(cursorTimeFormat)
+	case "priority":
+		return ticket.Priority
+	case "id":
+		return ""
+	}
+
+	return ticket.CreatedAt.Format(cursorTimeFormat)
+}
+
+// listCacheKey keys the list cache by filter. Writes bump the version, which
+// orphans every cached list at once instead of deleting keys one by one.
+func (t *TaskRepo) listCacheKey(ctx context.Context, filter model.FindAllParam) string {
+	version, err := t.rdb.Get(ctx, cacheKeyListVersion).Int64()
+	if err != nil {
+		version = 0
+	}
+
+	data, _ := json.Marshal(filter)
+	return fmt.Sprintf(cacheKeyList, version, helper.HashToken(string(data)))
+}
+
+func (t *TaskRepo) invalidateList(ctx context.Context) {
+	t.rdb.Incr(ctx, cacheKeyListVersion)
 }
 
 func (t *TaskRepo) FindById(ctx context.Context, id int64) (*model.Ticket, error) {
@@ -73,7 +226,7 @@ func (t *TaskRepo) FindById(ctx context.Context, id int64) (*model.Ticket, error
 	}
 
 	var ticket model.Ticket
-	err = t.db.WithContext(ctx).Where("deleted_at IS NULL").First(&ticket, id).Preload("Comments").Error
+	err = conn(ctx, t.db).Where("deleted_at IS NULL").First(&ticket, id).Preload("Comments").Error
 	if err != nil {
 		return nil, err
 	}
@@ -87,34 +240,44 @@ func (t *TaskRepo) FindById(ctx context.Context, id int64) (*model.Ticket, error
 }
 
 func (t *TaskRepo) Create(ctx context.Context, ticket model.Ticket) (*model.Ticket, error) {
-	err := t.db.WithContext(ctx).Create(&ticket).Error
+	err := conn(ctx, t.db).Create(&ticket).Error
 	if err != nil {
 		return nil, err
 	}
 
-	t.rdb.Del(ctx, cacheKeyAll)
+	afterCommit(ctx, t.invalidateList)
 
 	return &ticket, nil
 }
 
+// Update saves the editable fields and SLA targets of the ticket. The columns
+// are listed so that clearing one, such as a category or a due date that no
+// policy sets any more, is saved too.
 func (t *TaskRepo) Update(ctx context.Context, ticket model.Ticket) (*model.Ticket, error) {
-	err := t.db.WithContext(ctx).
+	err := conn(ctx, t.db).
 		Model(&model.Ticket{}).
 		Where("id = ?", ticket.ID).
+		Select(
+			"title", "description", "status", "priority", "assigned_to",
+			"category", "queue", "sla_policy_id", "first_response_due_by", "due_by",
+			"updated_at",
+		).
 		Updates(&ticket).Error
 
 	if err != nil {
 		return nil, err
 	}
 
-	t.rdb.Del(ctx, fmt.Sprintf(cacheKeyByID, ticket.ID))
-	t.rdb.Del(ctx, cacheKeyAll)
+	afterCommit(ctx, func(ctx context.Context) {
+		t.rdb.Del(ctx, fmt.Sprintf(cacheKeyByID, ticket.ID))
+		t.invalidateList(ctx)
+	})
 
 	return &ticket, nil
 }
 
 func (t *TaskRepo) Delete(ctx context.Context, id int64) error {
-	err := t.db.WithContext(ctx).
+	err := conn(ctx, t.db).
 		Model(&model.Ticket{}).
 		Where("id = ?", id).
 		Update("deleted_at", time.Now()).Error
@@ -123,8 +286,78 @@ func (t *TaskRepo) Delete(ctx context.Context, id int64) error {
 		return err
 	}
 
-	t.rdb.Del(ctx, fmt.Sprintf(cacheKeyByID, id))
-	t.rdb.Del(ctx, cacheKeyAll)
+	afterCommit(ctx, func(ctx context.Context) {
+		t.rdb.Del(ctx, fmt.Sprintf(cacheKeyByID, id))
+		t.invalidateList(ctx)
+	})
+
+	return nil
+}
+
+func (t *TaskRepo) SetFirstResponse(ctx context.Context, id int64, at time.Time) error {
+	result := conn(ctx, t.db).
+		Model(&model.Ticket{}).
+		Where("id = ? AND first_responded_at IS NULL", id).
+		Update("first_responded_at", at)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		afterCommit(ctx, func(ctx context.Context) {
+			t.rdb.Del(ctx, fmt.Sprintf(cacheKeyByID, id))
+		})
+	}
 
 	return nil
 }
+
+// slaBreachColumns maps an SLA target to its due date column and the column
+// recording the due date last warned about.
+var slaBreachColumns = map[string][2]string{
+	model.SLATargetFirstResponse: {"first_response_due_by", "first_response_warned_for"},
+	model.SLATargetResolution:    {"due_by", "due_by_warned_for"},
+}
+
+func (t *TaskRepo) ClaimSLABreaches(ctx context.Context, target string, before time.Time, limit int) ([]*model.Ticket, error) {
+	columns, ok := slaBreachColumns[target]
+	if !ok {
+		return nil, fmt.Errorf("unknown SLA target %q", target)
+	}
+	due, warned := columns[0], columns[1]
+
+	condition := ""
+	if target == model.SLATargetFirstResponse {
+		condition = "AND first_responded_at IS NULL"
+	}
+
+	// SKIP LOCKED lets several API instances scan at once without warning
+	// about the same ticket twice
+	query := fmt.Sprintf(`UPDATE tickets SET %[2]s = %[1]s WHERE id IN (
+		SELECT id FROM tickets
+		WHERE deleted_at IS NULL
+			AND status NOT IN ('pending', 'resolved', 'closed')
+			AND %[1]s <= ?
+			AND %[2]s IS DISTINCT FROM %[1]s
+			%[3]s
+		ORDER BY %[1]s
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	) RETURNING *`, due, warned, condition)
+
+	var tickets []*model.Ticket
+	if err := conn(ctx, t.db).Raw(query, before, limit).Scan(&tickets).Error; err != nil {
+		return nil, err
+	}
+
+	if len(tickets) > 0 {
+		afterCommit(ctx, func(ctx context.Context) {
+			for _, ticket := range tickets {
+				t.rdb.Del(ctx, fmt.Sprintf(cacheKeyByID, ticket.ID))
+			}
+		})
+	}
+
+	return tickets, nil
+}
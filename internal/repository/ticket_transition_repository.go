@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"helpdesk-ticketing-system/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type TicketTransitionRepo struct {
+	db *gorm.DB
+}
+
+func NewTicketTransitionRepo(db *gorm.DB) model.ITicketTransitionRepository {
+	return &TicketTransitionRepo{db: db}
+}
+
+func (t *TicketTransitionRepo) FindAll(ctx context.Context) ([]*model.TicketTransition, error) {
+	var transitions []*model.TicketTransition
+
+	err := t.db.WithContext(ctx).Order("id ASC").Find(&transitions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return transitions, nil
+}
+
+func (t *TicketTransitionRepo) FindById(ctx context.Context, id int64) (*model.TicketTransition, error) {
+	var transition model.TicketTransition
+
+	err := t.db.WithContext(ctx).First(&transition, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &transition, nil
+}
+
+// FindByStatuses returns nil without an error when the transition is not
+// configured.
+func (t *TicketTransitionRepo) FindByStatuses(ctx context.Context, from string, to string) (*model.TicketTransition, error) {
+	var transition model.TicketTransition
+
+	err := t.db.WithContext(ctx).
+		Where("from_status = ? AND to_status = ?", from, to).
+		First(&transition).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &transition, nil
+}
+
+func (t *TicketTransitionRepo) Create(ctx context.Context, transition model.TicketTransition) (*model.TicketTransition, error) {
+	err := t.db.WithContext(ctx).Create(&transition).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &transition, nil
+}
+
+func (t *TicketTransitionRepo) Update(ctx context.Context, transition model.TicketTransition) (*model.TicketTransition, error) {
+	err := t.db.WithContext(ctx).
+		Model(&model.TicketTransition{}).
+		Where("id = ?", transition.ID).
+		Updates(map[string]interface{}{
+			"roles":      transition.Roles,
+			"updated_at": transition.UpdatedAt,
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &transition, nil
+}
+
+func (t *TicketTransitionRepo) Delete(ctx context.Context, id int64) error {
+	err := t.db.WithContext(ctx).Delete(&model.TicketTransition{}, id).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,25 +2,42 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"time"
 
+	"helpdesk-ticketing-system/internal/helper"
 	"helpdesk-ticketing-system/internal/model"
 
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
+const (
+	cacheKeySession    = "session:%s"
+	cacheKeyStream     = "stream_ticket:%s"
+	sessionCacheMaxTTL = time.Minute * 5
+)
+
 type UserRepo struct {
-	db *gorm.DB
+	db  *gorm.DB
+	rdb *redis.Client
 }
 
-func NewUserRepo(db *gorm.DB) model.IUserRepository {
+func NewUserRepo(db *gorm.DB, rdb *redis.Client) model.IUserRepository {
 	return &UserRepo{
-		db: db,
+		db:  db,
+		rdb: rdb,
 	}
 }
 
+// sessionCacheKey hashes the token so raw JWTs never end up as Redis keys.
+func sessionCacheKey(token string) string {
+	return fmt.Sprintf(cacheKeySession, helper.HashToken(token))
+}
+
 func (r *UserRepo) FindAll(ctx context.Context, user model.User) ([]*model.User, error) {
 	var users []*model.User
 	query := r.db.WithContext(ctx).Model(&model.User{}).Where("deleted_at IS NULL")
@@ -31,6 +48,9 @@ func (r *UserRepo) FindAll(ctx context.Context, user model.User) ([]*model.User,
 	if user.Email != "" {
 		query = query.Where("email LIKE ?", "%"+user.Email+"%")
 	}
+	if user.Role != "" {
+		query = query.Where("role = ?", user.Role)
+	}
 
 	err := query.Find(&users).Error
 	if err != nil {
@@ -52,10 +72,26 @@ func (u *UserRepo) FindById(ctx context.Context, id int64) (*model.User, error)
 	return &user, nil
 }
 
+func (u *UserRepo) FindByIDs(ctx context.Context, ids []int64) ([]*model.User, error) {
+	var users []*model.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	err := u.db.WithContext(ctx).Where("id IN ?", ids).Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// FindByEmail only matches active users, so deleted accounts can neither log
+// in nor act as inbound mail senders.
 func (u *UserRepo) FindByEmail(ctx context.Context, email string) *model.User {
 	var user model.User
 
-	err := u.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	err := u.db.WithContext(ctx).Where("email = ? AND deleted_at IS NULL", email).First(&user).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			log.Printf("No user found with email: %s", email)
@@ -114,9 +150,19 @@ func (u *UserRepo) CreateSession(ctx context.Context, session model.UserSession)
 }
 
 func (u *UserRepo) FindSessionByToken(ctx context.Context, token string) (*model.UserSession, error) {
+	cacheKey := sessionCacheKey(token)
+
+	cached, err := u.rdb.Get(ctx, cacheKey).Result()
+	if err == nil {
+		var session model.UserSession
+		if err := json.Unmarshal([]byte(cached), &session); err == nil && session.ExpiresAt.After(time.Now()) {
+			return &session, nil
+		}
+	}
+
 	var session model.UserSession
-	err := u.db.WithContext(ctx).
-		Where("token = ? AND expires_at > ?", token, time.Now()).
+	err = u.db.WithContext(ctx).
+		Where("token = ? AND expires_at > ? AND revoked_at IS NULL", token, time.Now()).
 		First(&session).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -125,13 +171,115 @@ func (u *UserRepo) FindSessionByToken(ctx context.Context, token string) (*model
 	if err != nil {
 		return nil, err
 	}
+
+	ttl := time.Until(session.ExpiresAt)
+	if ttl > sessionCacheMaxTTL {
+		ttl = sessionCacheMaxTTL
+	}
+
+	data, err := json.Marshal(session)
+	if err == nil {
+		u.rdb.Set(ctx, cacheKey, data, ttl)
+	}
+
 	return &session, nil
 }
 
+func (u *UserRepo) FindSessionByRefreshToken(ctx context.Context, refreshTokenHash string) (*model.UserSession, error) {
+	var session model.UserSession
+	err := u.db.WithContext(ctx).
+		Where("refresh_token_hash = ?", refreshTokenHash).
+		First(&session).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("session not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// RevokeSession marks the session revoked and reports whether this call did
+// it, so two concurrent refreshes cannot both rotate the same token.
+func (u *UserRepo) RevokeSession(ctx context.Context, session model.UserSession) (bool, error) {
+	result := u.db.WithContext(ctx).
+		Model(&model.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", session.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	u.rdb.Del(ctx, sessionCacheKey(session.Token))
+
+	return result.RowsAffected > 0, nil
+}
+
+func (u *UserRepo) RevokeSessionFamily(ctx context.Context, familyID string) error {
+	var sessions []*model.UserSession
+	err := u.db.WithContext(ctx).Where("family_id = ?", familyID).Find(&sessions).Error
+	if err != nil {
+		return err
+	}
+
+	err = u.db.WithContext(ctx).
+		Model(&model.UserSession{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		u.rdb.Del(ctx, sessionCacheKey(session.Token))
+	}
+
+	return nil
+}
+
 func (u *UserRepo) DeleteSession(ctx context.Context, token string) error {
 	err := u.db.WithContext(ctx).Where("token = ?", token).Delete(&model.UserSession{}).Error
 	if err != nil {
 		return err
 	}
+
+	u.rdb.Del(ctx, sessionCacheKey(token))
+
 	return nil
 }
+
+func (u *UserRepo) DeleteSessionsByUserID(ctx context.Context, userID int64) error {
+	var sessions []*model.UserSession
+	err := u.db.WithContext(ctx).Where("user_id = ?", userID).Find(&sessions).Error
+	if err != nil {
+		return err
+	}
+
+	err = u.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.UserSession{}).Error
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		u.rdb.Del(ctx, sessionCacheKey(session.Token))
+	}
+
+	return nil
+}
+
+func (u *UserRepo) SaveStreamTicket(ctx context.Context, ticketHash string, accessToken string, ttl time.Duration) error {
+	return u.rdb.Set(ctx, fmt.Sprintf(cacheKeyStream, ticketHash), accessToken, ttl).Err()
+}
+
+func (u *UserRepo) TakeStreamTicket(ctx context.Context, ticketHash string) (string, error) {
+	token, err := u.rdb.GetDel(ctx, fmt.Sprintf(cacheKeyStream, ticketHash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const defaultWebhookDeliveryLimit = 20
+
+type WebhookDeliveryRepo struct {
+	db *gorm.DB
+}
+
+func NewWebhookDeliveryRepo(db *gorm.DB) model.IWebhookDeliveryRepository {
+	return &WebhookDeliveryRepo{db: db}
+}
+
+func (w *WebhookDeliveryRepo) FindById(ctx context.Context, id int64) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+
+	err := conn(ctx, w.db).First(&delivery, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// FindAllBySubscriptionID returns a page of the subscription's deliveries,
+// newest first.
+func (w *WebhookDeliveryRepo) FindAllBySubscriptionID(ctx context.Context, subscriptionID int64, param model.WebhookDeliveryParam) (*model.WebhookDeliveryPage, error) {
+	if param.Limit <= 0 {
+		param.Limit = defaultWebhookDeliveryLimit
+	}
+
+	query := conn(ctx, w.db).
+		Model(&model.WebhookDelivery{}).
+		Where("subscription_id = ?", subscriptionID)
+	if param.Status != "" {
+		query = query.Where("status = ?", param.Status)
+	}
+
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if param.Cursor != "" {
+		_, id, err := helper.DecodeCursor(param.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("id < ?", id)
+	}
+
+	var deliveries []*model.WebhookDelivery
+	err = query.
+		Order("id DESC").
+		Limit(int(param.Limit) + 1).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.WebhookDeliveryPage{
+		Deliveries: deliveries,
+		Meta: model.PageMeta{
+			Total: total,
+			Limit: param.Limit,
+		},
+	}
+
+	if int64(len(deliveries)) > param.Limit {
+		page.Deliveries = deliveries[:param.Limit]
+		last := page.Deliveries[len(page.Deliveries)-1]
+		page.Meta.NextCursor = helper.EncodeCursor("", last.ID)
+	}
+
+	return page, nil
+}
+
+func (w *WebhookDeliveryRepo) Create(ctx context.Context, delivery *model.WebhookDelivery) error {
+	err := conn(ctx, w.db).Create(delivery).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *WebhookDeliveryRepo) UpdateResult(ctx context.Context, id int64, result model.WebhookDeliveryResult) error {
+	updates := map[string]interface{}{
+		"status":          result.Status,
+		"attempts":        result.Attempts,
+		"response_status": result.ResponseStatus,
+		"last_error":      result.LastError,
+		"updated_at":      time.Now(),
+	}
+	if result.Status == model.WebhookDeliverySent {
+		updates["delivered_at"] = time.Now()
+	}
+
+	err := conn(ctx, w.db).
+		Model(&model.WebhookDelivery{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
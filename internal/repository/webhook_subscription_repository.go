@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"helpdesk-ticketing-system/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type WebhookSubscriptionRepo struct {
+	db *gorm.DB
+}
+
+func NewWebhookSubscriptionRepo(db *gorm.DB) model.IWebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepo{db: db}
+}
+
+func (w *WebhookSubscriptionRepo) FindAll(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	var subscriptions []*model.WebhookSubscription
+
+	err := conn(ctx, w.db).Order("id ASC").Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (w *WebhookSubscriptionRepo) FindActiveByEvent(ctx context.Context, eventType string) ([]*model.WebhookSubscription, error) {
+	var subscriptions []*model.WebhookSubscription
+
+	err := conn(ctx, w.db).
+		Where("active AND ? = ANY(events)", eventType).
+		Order("id ASC").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (w *WebhookSubscriptionRepo) FindById(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+
+	err := conn(ctx, w.db).First(&subscription, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (w *WebhookSubscriptionRepo) Create(ctx context.Context, subscription model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	err := conn(ctx, w.db).Create(&subscription).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (w *WebhookSubscriptionRepo) Update(ctx context.Context, subscription model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	err := conn(ctx, w.db).
+		Model(&model.WebhookSubscription{}).
+		Where("id = ?", subscription.ID).
+		Updates(map[string]interface{}{
+			"url":        subscription.URL,
+			"events":     subscription.Events,
+			"secret":     subscription.Secret,
+			"active":     subscription.Active,
+			"updated_at": subscription.UpdatedAt,
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (w *WebhookSubscriptionRepo) Delete(ctx context.Context, id int64) error {
+	err := conn(ctx, w.db).Delete(&model.WebhookSubscription{}, id).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,8 +2,15 @@ package usecase
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"helpdesk-ticketing-system/internal/config"
 	"helpdesk-ticketing-system/internal/helper"
 	"helpdesk-ticketing-system/internal/model"
+	"io"
+	"mime"
+	"path/filepath"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -11,11 +18,31 @@ import (
 
 type AttachmentUsecase struct {
 	attachmentRepo model.IAttachmentRepository
+	ticketRepo     model.ITicketRepository
+	blobStorage    model.IBlobStorage
+	malwareScanner model.IMalwareScanner
+	outboxUsecase  model.IOutboxUsecase
+	eventBus       model.IEventBus
+	unitOfWork     model.IUnitOfWork
 }
 
-func NewAttachmentUsecase(attachmentRepo model.IAttachmentRepository) model.IAttachmentUsecase {
+func NewAttachmentUsecase(
+	attachmentRepo model.IAttachmentRepository,
+	ticketRepo model.ITicketRepository,
+	blobStorage model.IBlobStorage,
+	malwareScanner model.IMalwareScanner,
+	outboxUsecase model.IOutboxUsecase,
+	eventBus model.IEventBus,
+	unitOfWork model.IUnitOfWork,
+) model.IAttachmentUsecase {
 	return &AttachmentUsecase{
 		attachmentRepo: attachmentRepo,
+		ticketRepo:     ticketRepo,
+		blobStorage:    blobStorage,
+		malwareScanner: malwareScanner,
+		outboxUsecase:  outboxUsecase,
+		eventBus:       eventBus,
+		unitOfWork:     unitOfWork,
 	}
 }
 
@@ -24,6 +51,12 @@ func (a *AttachmentUsecase) FindAllByTicketID(ctx context.Context, ticketID int6
 		"ticketID": ticketID,
 	})
 
+	_, err := findReadableTicket(ctx, a.ticketRepo, ticketID)
+	if err != nil {
+		log.Error("Failed to fetch ticket: ", err)
+		return nil, err
+	}
+
 	attachments, err := a.attachmentRepo.FindAllByTicketID(ctx, ticketID)
 	if err != nil {
 		log.Error("Failed to fetch attachments: ", err)
@@ -33,28 +66,437 @@ func (a *AttachmentUsecase) FindAllByTicketID(ctx context.Context, ticketID int6
 	return attachments, nil
 }
 
-func (a *AttachmentUsecase) Create(ctx context.Context, in model.CreateAttachmentInput) error {
+func (a *AttachmentUsecase) Upload(ctx context.Context, in model.UploadAttachmentInput) (*model.Attachment, error) {
 	log := logrus.WithFields(logrus.Fields{
-		"input": in,
+		"ticket_id": in.TicketID,
+		"filename":  in.Filename,
+		"size":      in.Size,
 	})
 
 	err := helper.Validator.Struct(in)
 	if err != nil {
 		log.Error("Validation error: ", err)
-		return err
+		return nil, err
+	}
+
+	if maxSize := config.StorageMaxUploadSize(); maxSize > 0 && in.Size > maxSize {
+		log.Error("Attachment exceeds the upload size limit")
+		return nil, model.ErrFileTooLarge
+	}
+
+	ticket, err := findReadableTicket(ctx, a.ticketRepo, in.TicketID)
+	if err != nil {
+		log.Error("Failed to fetch ticket: ", err)
+		return nil, err
+	}
+
+	filename := helper.CleanFilename(in.Filename)
+
+	mimeType, content, err := helper.SniffContentType(in.Content)
+	if err != nil {
+		log.Error("Failed to read attachment: ", err)
+		return nil, err
+	}
+
+	// the claimed type is only checked against the deny list, so a client
+	// cannot get a file past the allow list by lying about it
+	if !helper.FileTypeAllowed(mimeType, filename, config.StorageAllowedTypes(), config.StorageDeniedTypes()) ||
+		!helper.FileTypeAllowed(in.ContentType, filename, nil, config.StorageDeniedTypes()) {
+		log.WithField("mime_type", mimeType).Error("Attachment type is not allowed")
+		return nil, model.ErrFileTypeNotAllowed
+	}
+
+	// uploads made by the system, such as inbound email, have no actor
+	actorID, _ := helper.GetUserID(ctx)
+
+	key, err := helper.AttachmentObjectKey(in.TicketID, filename)
+	if err != nil {
+		log.Error("Failed to generate object key: ", err)
+		return nil, err
+	}
+
+	hash := sha256.New()
+	counter := &byteCounter{}
+	err = a.blobStorage.Put(ctx, key, io.TeeReader(io.LimitReader(content, in.Size), io.MultiWriter(hash, counter)), in.Size, mimeType)
+	if err != nil {
+		log.Error("Failed to store attachment: ", err)
+		return nil, err
 	}
 
-	attachment := model.Attachment{
+	attachment := &model.Attachment{
 		TicketID:   in.TicketID,
-		FilePath:   in.FilePath,
+		ObjectKey:  key,
+		Filename:   filename,
+		MimeType:   mimeType,
+		Size:       counter.n,
+		SHA256:     hex.EncodeToString(hash.Sum(nil)),
+		ScanStatus: model.AttachmentScanQuarantined,
+		UploadedBy: actorID,
 		UploadedAt: time.Now(),
 	}
 
-	err = a.attachmentRepo.Create(ctx, attachment)
+	err = checkUploadSize(content, in.Size, counter.n)
+	if err != nil {
+		log.Error("Failed to save attachment: ", err)
+		a.deleteOrphan(ctx, key)
+		return nil, err
+	}
+
+	err = a.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		// the row goes if this or an enclosing unit of work rolls back, such
+		// as inbound mail that fails after its attachments were stored, and
+		// then nothing refers to the blob
+		a.unitOfWork.AfterRollback(ctx, func(ctx context.Context) {
+			a.deleteOrphan(ctx, key)
+		})
+
+		err := a.attachmentRepo.Create(ctx, attachment)
+		if err != nil {
+			log.Error("Failed to create attachment: ", err)
+			return err
+		}
+
+		err = a.outboxUsecase.Enqueue(ctx, config.NotificationExchange, config.AttachmentScanQueue, model.AttachmentScanMessage{
+			AttachmentID: attachment.ID,
+		})
+		if err != nil {
+			log.Error("Failed to enqueue attachment scan: ", err)
+			return err
+		}
+
+		return a.eventBus.Publish(ctx, &model.AttachmentAdded{
+			DomainEventMeta: model.DomainEventMeta{ActorID: actorID},
+			Ticket:          ticket,
+			Attachment:      attachment,
+		})
+	})
+	if err != nil {
+		log.Error("Failed to save attachment: ", err)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func (a *AttachmentUsecase) Scan(ctx context.Context, id int64) (*model.Attachment, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"id": id,
+	})
+
+	attachment, err := a.attachmentRepo.FindById(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch attachment: ", err)
+		return nil, err
+	}
+
+	// a redelivered job finds the attachment already scanned
+	if attachment.ScanStatus != model.AttachmentScanQuarantined {
+		return attachment, nil
+	}
+
+	content, err := a.blobStorage.Get(ctx, attachment.ObjectKey)
+	if err != nil {
+		log.Error("Failed to read attachment: ", err)
+		return nil, err
+	}
+	defer content.Close()
+
+	result, err := a.malwareScanner.Scan(ctx, content)
+	if err != nil {
+		log.Error("Failed to scan attachment: ", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	attachment.ScannedAt = &now
+	attachment.ScanStatus = model.AttachmentScanClean
+	if !result.Infected {
+		updated, err := a.attachmentRepo.UpdateScanResult(ctx, attachment)
+		if err != nil {
+			log.Error("Failed to update attachment scan result: ", err)
+			return nil, err
+		}
+		if !updated {
+			return a.scannedElsewhere(ctx, id)
+		}
+
+		return attachment, nil
+	}
+
+	attachment.ScanStatus = model.AttachmentScanInfected
+	attachment.ScanSignature = result.Signature
+	log.WithField("signature", result.Signature).Warn("Malware found in attachment")
+
+	ticket, err := a.ticketRepo.FindById(ctx, attachment.TicketID)
+	if err != nil {
+		log.Error("Failed to fetch ticket: ", err)
+		return nil, err
+	}
+	if ticket == nil {
+		log.Error("Ticket not found")
+		return nil, model.ErrTicketNotFound
+	}
+
+	updated := false
+	err = a.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		var err error
+		updated, err = a.attachmentRepo.UpdateScanResult(ctx, attachment)
+		if err != nil || !updated {
+			return err
+		}
+
+		return a.eventBus.Publish(ctx, &model.AttachmentInfected{
+			Ticket:     ticket,
+			Attachment: attachment,
+		})
+	})
+	if err != nil {
+		log.Error("Failed to update attachment scan result: ", err)
+		return nil, err
+	}
+	if !updated {
+		return a.scannedElsewhere(ctx, id)
+	}
+
+	return attachment, nil
+}
+
+// deleteOrphan removes a stored blob that no attachment row refers to.
+func (a *AttachmentUsecase) deleteOrphan(ctx context.Context, key string) {
+	err := a.blobStorage.Delete(context.WithoutCancel(ctx), key)
+	if err != nil {
+		logrus.WithField("object_key", key).Error("Failed to delete orphaned attachment: ", err)
+	}
+}
+
+// scannedElsewhere returns the stored result when another worker finished
+// scanning the attachment first, such as after a rescan was queued while the
+// original job was still retrying.
+func (a *AttachmentUsecase) scannedElsewhere(ctx context.Context, id int64) (*model.Attachment, error) {
+	logrus.WithField("id", id).Info("Attachment was already scanned by another worker")
+
+	return a.attachmentRepo.FindById(ctx, id)
+}
+
+// defaultRescanLimit is how many attachments RescanQuarantined queues when
+// the input sets no limit.
+const defaultRescanLimit = 100
+
+func (a *AttachmentUsecase) RescanQuarantined(ctx context.Context, in model.RescanAttachmentsInput) (int, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"input": in,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can rescan attachments")
+		return 0, model.ErrForbidden
+	}
+
+	err := helper.Validator.Struct(in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return 0, err
+	}
+
+	limit := in.Limit
+	if limit == 0 {
+		limit = defaultRescanLimit
+	}
+
+	// attachments younger than the whole retry schedule may still have a
+	// scan job waiting in a delay queue
+	var retryWindow time.Duration
+	for _, delay := range config.RetryDelays {
+		retryWindow += delay
+	}
+
+	attachments, err := a.attachmentRepo.FindQuarantined(ctx, time.Now().Add(-retryWindow), limit)
+	if err != nil {
+		log.Error("Failed to fetch quarantined attachments: ", err)
+		return 0, err
+	}
+
+	err = a.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		for _, attachment := range attachments {
+			err := a.outboxUsecase.Enqueue(ctx, config.NotificationExchange, config.AttachmentScanQueue, model.AttachmentScanMessage{
+				AttachmentID: attachment.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Error("Failed to enqueue attachment scans: ", err)
+		return 0, err
+	}
+
+	log.WithField("count", len(attachments)).Info("Queued quarantined attachments for another scan")
+
+	return len(attachments), nil
+}
+
+func (a *AttachmentUsecase) Download(ctx context.Context, id int64) (*model.AttachmentDownload, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"id": id,
+	})
+
+	attachment, err := a.findReadable(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch attachment: ", err)
+		return nil, err
+	}
+
+	return a.open(ctx, attachment)
+}
+
+func (a *AttachmentUsecase) SignDownloadURL(ctx context.Context, id int64) (*model.SignedDownloadURL, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"id": id,
+	})
+
+	attachment, err := a.findReadable(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch attachment: ", err)
+		return nil, err
+	}
+
+	err = checkScanned(attachment)
+	if err != nil {
+		log.Error("Attachment cannot be downloaded: ", err)
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(config.StorageSignedURLTTL()).Truncate(time.Second)
+	expires := expiresAt.Unix()
+
+	return &model.SignedDownloadURL{
+		URL: fmt.Sprintf("/v1/attachment/%d/download/signed?expires=%d&signature=%s",
+			attachment.ID, expires, helper.SignAttachmentDownload(attachment.ID, expires)),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (a *AttachmentUsecase) DownloadSigned(ctx context.Context, id int64, expires int64, signature string) (*model.AttachmentDownload, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"id":      id,
+		"expires": expires,
+	})
+
+	if time.Now().Unix() > expires || !helper.VerifyAttachmentDownload(id, expires, signature) {
+		log.Error("Invalid or expired download link")
+		return nil, model.ErrForbidden
+	}
+
+	attachment, err := a.attachmentRepo.FindById(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch attachment: ", err)
+		return nil, err
+	}
+
+	// like the authenticated download, the link stops working once the
+	// ticket is deleted
+	ticket, err := a.ticketRepo.FindById(ctx, attachment.TicketID)
+	if err != nil {
+		log.Error("Failed to fetch ticket: ", err)
+		return nil, err
+	}
+	if ticket == nil || (ticket.DeletedAt != nil && !ticket.DeletedAt.IsZero()) {
+		log.Error("Ticket is deleted or does not exist")
+		return nil, model.ErrTicketNotFound
+	}
+
+	return a.open(ctx, attachment)
+}
+
+// findReadable returns the attachment if the caller can see its ticket.
+func (a *AttachmentUsecase) findReadable(ctx context.Context, id int64) (*model.Attachment, error) {
+	attachment, err := a.attachmentRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = findReadableTicket(ctx, a.ticketRepo, attachment.TicketID)
+	if err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// open prepares the blob for streaming. The content type is the one detected
+// on upload; attachments from before detection fall back to the file
+// extension, then to the storage, and are left for the HTTP layer to sniff
+// otherwise.
+func (a *AttachmentUsecase) open(ctx context.Context, attachment *model.Attachment) (*model.AttachmentDownload, error) {
+	err := checkScanned(attachment)
+	if err != nil {
+		logrus.WithField("id", attachment.ID).Error("Attachment cannot be downloaded: ", err)
+		return nil, err
+	}
+
+	info, err := a.blobStorage.Stat(ctx, attachment.ObjectKey)
 	if err != nil {
-		log.Error("Failed to create attachment: ", err)
-		return err
+		logrus.WithField("object_key", attachment.ObjectKey).Error("Failed to stat attachment: ", err)
+		return nil, err
+	}
+
+	contentType := attachment.MimeType
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = mime.TypeByExtension(filepath.Ext(attachment.Filename))
+	}
+	if contentType == "" {
+		contentType = info.ContentType
+	}
+
+	modTime := info.ModTime
+	if modTime.IsZero() {
+		modTime = attachment.UploadedAt
+	}
+
+	return &model.AttachmentDownload{
+		Attachment:  attachment,
+		ContentType: contentType,
+		Size:        info.Size,
+		ModTime:     modTime,
+		Content:     helper.NewBlobReader(ctx, a.blobStorage, attachment.ObjectKey, info.Size),
+	}, nil
+}
+
+// checkScanned lets only attachments the scanner found clean be downloaded.
+func checkScanned(attachment *model.Attachment) error {
+	switch attachment.ScanStatus {
+	case model.AttachmentScanClean:
+		return nil
+	case model.AttachmentScanInfected:
+		return model.ErrAttachmentInfected
+	}
+
+	return model.ErrAttachmentQuarantined
+}
+
+// checkUploadSize reports an error unless the upload held exactly the size it
+// declared. read is what was stored and content is what is left of it.
+func checkUploadSize(content io.Reader, size int64, read int64) error {
+	if read < size {
+		return fmt.Errorf("upload ended after %d of %d bytes", read, size)
+	}
+
+	n, _ := io.ReadFull(content, make([]byte, 1))
+	if n > 0 {
+		return fmt.Errorf("upload is longer than its declared %d bytes", size)
 	}
 
 	return nil
 }
+
+type byteCounter struct {
+	n int64
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type BusinessCalendarUsecase struct {
+	businessCalendarRepo model.IBusinessCalendarRepository
+}
+
+func NewBusinessCalendarUsecase(businessCalendarRepo model.IBusinessCalendarRepository) model.IBusinessCalendarUsecase {
+	return &BusinessCalendarUsecase{businessCalendarRepo: businessCalendarRepo}
+}
+
+func (b *BusinessCalendarUsecase) FindAll(ctx context.Context) ([]*model.BusinessCalendar, error) {
+	if !isAdmin(ctx) {
+		logrus.Error("Only admins can manage business calendars")
+		return nil, model.ErrForbidden
+	}
+
+	calendars, err := b.businessCalendarRepo.FindAll(ctx)
+	if err != nil {
+		logrus.Error("Failed to fetch business calendars: ", err)
+		return nil, err
+	}
+
+	return calendars, nil
+}
+
+func (b *BusinessCalendarUsecase) FindById(ctx context.Context, id int64) (*model.BusinessCalendar, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"id": id,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage business calendars")
+		return nil, model.ErrForbidden
+	}
+
+	calendar, err := b.businessCalendarRepo.FindById(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch business calendar by ID: ", err)
+		return nil, err
+	}
+
+	if calendar == nil {
+		log.Error("Business calendar not found")
+		return nil, errors.New("business calendar not found")
+	}
+
+	return calendar, nil
+}
+
+func (b *BusinessCalendarUsecase) Create(ctx context.Context, in model.CreateBusinessCalendarInput) (*model.BusinessCalendar, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"input": in,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage business calendars")
+		return nil, model.ErrForbidden
+	}
+
+	err := helper.Validator.Struct(in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	hours, holidays, err := buildBusinessCalendar(in.Hours, in.Holidays)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	calendar, err := b.businessCalendarRepo.Create(ctx, model.BusinessCalendar{
+		Name:      in.Name,
+		Timezone:  in.Timezone,
+		IsDefault: in.IsDefault,
+		Hours:     hours,
+		Holidays:  holidays,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Error("Failed to create business calendar: ", err)
+		return nil, err
+	}
+
+	return calendar, nil
+}
+
+func (b *BusinessCalendarUsecase) Update(ctx context.Context, id int64, in model.UpdateBusinessCalendarInput) (*model.BusinessCalendar, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"id":    id,
+		"input": in,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage business calendars")
+		return nil, model.ErrForbidden
+	}
+
+	err := helper.Validator.Struct(in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	hours, holidays, err := buildBusinessCalendar(in.Hours, in.Holidays)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	existingCalendar, err := b.businessCalendarRepo.FindById(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch business calendar: ", err)
+		return nil, err
+	}
+
+	calendar, err := b.businessCalendarRepo.Update(ctx, model.BusinessCalendar{
+		ID:        id,
+		Name:      in.Name,
+		Timezone:  in.Timezone,
+		IsDefault: in.IsDefault,
+		Hours:     hours,
+		Holidays:  holidays,
+		CreatedAt: existingCalendar.CreatedAt,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Error("Failed to update business calendar: ", err)
+		return nil, err
+	}
+
+	return calendar, nil
+}
+
+func (b *BusinessCalendarUsecase) Delete(ctx context.Context, id int64) error {
+	log := logrus.WithFields(logrus.Fields{
+		"id": id,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage business calendars")
+		return model.ErrForbidden
+	}
+
+	_, err := b.businessCalendarRepo.FindById(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch business calendar: ", err)
+		return err
+	}
+
+	err = b.businessCalendarRepo.Delete(ctx, id)
+	if err != nil {
+		log.Error("Failed to delete business calendar: ", err)
+		return err
+	}
+
+	log.Info("Successfully deleted business calendar with ID: ", id)
+	return nil
+}
+
+func buildBusinessCalendar(hoursIn []model.BusinessHourInput, holidaysIn []model.BusinessHolidayInput) ([]model.BusinessHour, []model.BusinessHoliday, error) {
+	hours := make([]model.BusinessHour, 0, len(hoursIn))
+	for _, hour := range hoursIn {
+		// "HH:MM" strings compare correctly as text
+		if hour.EndTime <= hour.StartTime {
+			return nil, nil, errors.New("business hours must end after they start")
+		}
+
+		hours = append(hours, model.BusinessHour{
+			Weekday:   hour.Weekday,
+			StartTime: hour.StartTime,
+			EndTime:   hour.EndTime,
+		})
+	}
+
+	holidays := make([]model.BusinessHoliday, 0, len(holidaysIn))
+	for _, holiday := range holidaysIn {
+		date, err := time.Parse("2006-01-02", holiday.Date)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		holidays = append(holidays, model.BusinessHoliday{
+			Date: date,
+			Name: holiday.Name,
+		})
+	}
+
+	return hours, holidays, nil
+}
@@ -11,10 +11,23 @@ import (
 
 type CommentUsecase struct {
 	commentRepo model.ICommentRepository
+	ticketRepo  model.ITicketRepository
+	eventBus    model.IEventBus
+	unitOfWork  model.IUnitOfWork
 }
 
-func NewCommentUsecase(commentRepo model.ICommentRepository) model.ICommentUsecase {
-	return &CommentUsecase{commentRepo: commentRepo}
+func NewCommentUsecase(
+	commentRepo model.ICommentRepository,
+	ticketRepo model.ITicketRepository,
+	eventBus model.IEventBus,
+	unitOfWork model.IUnitOfWork,
+) model.ICommentUsecase {
+	return &CommentUsecase{
+		commentRepo: commentRepo,
+		ticketRepo:  ticketRepo,
+		eventBus:    eventBus,
+		unitOfWork:  unitOfWork,
+	}
 }
 
 func (c *CommentUsecase) FindAll(ctx context.Context, comment model.Comment) ([]*model.Comment, error) {
@@ -22,6 +35,19 @@ func (c *CommentUsecase) FindAll(ctx context.Context, comment model.Comment) ([]
 		"comment": comment,
 	})
 
+	// comments are listed across tickets, so only agents and admins, who can
+	// see every ticket, may list them
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		log.Error("Failed to get claims: ", err)
+		return nil, err
+	}
+
+	if !canReadAllTickets(claims) {
+		log.Error("You are not authorized to list comments")
+		return nil, model.ErrForbidden
+	}
+
 	comments, err := c.commentRepo.FindAll(ctx, comment)
 	if err != nil {
 		log.Error("Failed to fetch comments: ", err)
@@ -47,6 +73,12 @@ func (c *CommentUsecase) FindById(ctx context.Context, id int64) (*model.Comment
 		return nil, errors.New("comment not found")
 	}
 
+	_, err = findReadableTicket(ctx, c.ticketRepo, comment.TicketID)
+	if err != nil {
+		log.Error("Failed to fetch ticket: ", err)
+		return nil, err
+	}
+
 	return comment, nil
 }
 
@@ -67,15 +99,33 @@ func (c *CommentUsecase) Create(ctx context.Context, in model.CreateCommentInput
 		return &model.Comment{}, err
 	}
 
+	ticket, err := findReadableTicket(ctx, c.ticketRepo, in.TicketId)
+	if err != nil {
+		log.Error("Failed to fetch ticket: ", err)
+		return &model.Comment{}, err
+	}
+
 	comment := model.Comment{
 		UserID:   userID,
 		TicketID: in.TicketId,
 		Content:  in.Content,
 	}
 
-	comments, err := c.commentRepo.Create(ctx, comment)
+	var comments *model.Comment
+	err = c.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		comments, err = c.commentRepo.Create(ctx, comment)
+		if err != nil {
+			log.Error("Failed to create comment: ", err)
+			return err
+		}
+
+		return c.eventBus.Publish(ctx, &model.CommentAdded{
+			DomainEventMeta: model.DomainEventMeta{ActorID: userID},
+			Ticket:          ticket,
+			Comment:         comments,
+		})
+	})
 	if err != nil {
-		log.Error("Failed to create comment: ", err)
 		return &model.Comment{}, err
 	}
 
@@ -112,7 +162,13 @@ func (c *CommentUsecase) Update(ctx context.Context, id int64, in model.UpdateCo
 
 	if exitingComment.UserID != userID {
 		log.Error("You are not authorized to update this comment")
-		return &model.Comment{}, errors.New("you are not authorized to update this comment")
+		return &model.Comment{}, model.ErrForbidden
+	}
+
+	_, err = findReadableTicket(ctx, c.ticketRepo, in.TicketId)
+	if err != nil {
+		log.Error("Failed to fetch ticket: ", err)
+		return &model.Comment{}, err
 	}
 
 	comments, err := c.commentRepo.Update(ctx, model.Comment{
@@ -151,6 +207,17 @@ func (c *CommentUsecase) Delete(ctx context.Context, id int64) error {
 		return errors.New("comment is already deleted")
 	}
 
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		log.Error("Failed to get claims: ", err)
+		return err
+	}
+
+	if !canDeleteComment(claims, comment) {
+		log.Error("You are not authorized to delete this comment")
+		return model.ErrForbidden
+	}
+
 	err = c.commentRepo.Delete(ctx, id)
 	if err != nil {
 		log.Error("Failed to delete comment: ", err)
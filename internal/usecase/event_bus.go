@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EventBus dispatches domain events to the handlers registered in process
+// and, when an outbox is given, also publishes them to the domain event
+// exchange for consumers outside the app. Handlers are registered at start
+// up, before the first event is published.
+type EventBus struct {
+	handlers      map[string][]model.DomainEventHandler
+	afterCommit   map[string][]model.DomainEventHandler
+	outboxUsecase model.IOutboxUsecase
+	unitOfWork    model.IUnitOfWork
+}
+
+// NewEventBus returns a bus that only dispatches in process when
+// outboxUsecase is nil.
+func NewEventBus(outboxUsecase model.IOutboxUsecase, unitOfWork model.IUnitOfWork) model.IEventBus {
+	return &EventBus{
+		handlers:      make(map[string][]model.DomainEventHandler),
+		afterCommit:   make(map[string][]model.DomainEventHandler),
+		outboxUsecase: outboxUsecase,
+		unitOfWork:    unitOfWork,
+	}
+}
+
+func (b *EventBus) Subscribe(name string, handler model.DomainEventHandler) {
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+func (b *EventBus) SubscribeAfterCommit(name string, handler model.DomainEventHandler) {
+	b.afterCommit[name] = append(b.afterCommit[name], handler)
+}
+
+// Publish runs the events' handlers in one transaction, joining the one in
+// ctx, in the order the events and handlers were given.
+func (b *EventBus) Publish(ctx context.Context, events ...model.DomainEvent) error {
+	for _, event := range events {
+		meta := event.EventMeta()
+		if meta.ID == "" {
+			id, err := helper.RandomToken(16)
+			if err != nil {
+				return err
+			}
+			meta.ID = id
+		}
+		if meta.OccurredAt.IsZero() {
+			meta.OccurredAt = time.Now()
+		}
+	}
+
+	return b.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		for _, event := range events {
+			err := b.dispatch(ctx, event)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (b *EventBus) dispatch(ctx context.Context, event model.DomainEvent) error {
+	log := logrus.WithFields(logrus.Fields{
+		"event":    event.EventName(),
+		"event_id": event.EventMeta().ID,
+	})
+
+	for _, handler := range b.handlers[event.EventName()] {
+		err := handler(ctx, event)
+		if err != nil {
+			log.Error("Domain event handler failed: ", err)
+			return err
+		}
+	}
+
+	for _, handler := range b.afterCommit[event.EventName()] {
+		b.unitOfWork.AfterCommit(ctx, func(ctx context.Context) {
+			err := handler(context.WithoutCancel(ctx), event)
+			if err != nil {
+				log.Error("Domain event handler failed after commit: ", err)
+			}
+		})
+	}
+
+	if b.outboxUsecase == nil {
+		return nil
+	}
+
+	return b.outboxUsecase.Enqueue(ctx, config.DomainEventExchange, event.EventName(), model.DomainEventMessage{
+		Name:  event.EventName(),
+		Event: event,
+	})
+}
@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+const maxInboundSubjectLength = 255
+
+type InboundEmailUsecase struct {
+	inboundMessageRepo model.IInboundMessageRepository
+	userRepo           model.IUserRepository
+	ticketUsecase      model.ITicketUsecase
+	commentUsecase     model.ICommentUsecase
+	attachmentUsecase  model.IAttachmentUsecase
+	unitOfWork         model.IUnitOfWork
+}
+
+func NewInboundEmailUsecase(
+	inboundMessageRepo model.IInboundMessageRepository,
+	userRepo model.IUserRepository,
+	ticketUsecase model.ITicketUsecase,
+	commentUsecase model.ICommentUsecase,
+	attachmentUsecase model.IAttachmentUsecase,
+	unitOfWork model.IUnitOfWork,
+) model.IInboundEmailUsecase {
+	return &InboundEmailUsecase{
+		inboundMessageRepo: inboundMessageRepo,
+		userRepo:           userRepo,
+		ticketUsecase:      ticketUsecase,
+		commentUsecase:     commentUsecase,
+		attachmentUsecase:  attachmentUsecase,
+		unitOfWork:         unitOfWork,
+	}
+}
+
+// Process turns an inbound email into a comment when it replies to a ticket
+// the sender can see, and into a new ticket otherwise. Mail that was already
+// processed returns the earlier result, and automatic replies return
+// ErrAutoReply. Mail that would fail the same way on every attempt returns
+// ErrInboundRejected; any other error is worth retrying.
+func (i *InboundEmailUsecase) Process(ctx context.Context, email *model.InboundEmail) (*model.InboundMessage, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"message_id": email.MessageID,
+		"from":       email.From,
+		"subject":    email.Subject,
+	})
+
+	if email.AutoSubmitted {
+		log.Info("Ignoring automatic reply")
+		return nil, model.ErrAutoReply
+	}
+
+	err := helper.Validator.Var(email.From, "required,email")
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, fmt.Errorf("%w: %s", model.ErrInboundRejected, err)
+	}
+
+	if email.MessageID == "" {
+		token, err := helper.RandomToken(16)
+		if err != nil {
+			return nil, err
+		}
+		email.MessageID = token + "@" + config.InboundSMTPDomain()
+	}
+
+	existing, err := i.inboundMessageRepo.FindByMessageID(ctx, email.MessageID)
+	if err != nil {
+		log.Error("Failed to fetch inbound message: ", err)
+		return nil, err
+	}
+	if existing != nil {
+		log.Info("Inbound message was already processed")
+		return existing, nil
+	}
+
+	sender, err := i.findOrCreateSender(ctx, email)
+	if err != nil {
+		log.Error("Failed to resolve sender: ", err)
+		return nil, inboundError(err)
+	}
+
+	// tickets and comments are created as the sender. From is trivial to
+	// forge, so the sender only keeps a staff role when the receiving MTA
+	// vouches for the address
+	role := model.RoleCustomer
+	if helper.SenderAuthenticated(email, config.InboundAuthservID()) {
+		role = sender.Role
+	} else if sender.Role != model.RoleCustomer {
+		log.Warn("Sender is not authenticated, acting as a customer")
+	}
+
+	senderCtx := context.WithValue(ctx, model.BearerAuthKey, model.CustomClaims{
+		UserID: sender.ID,
+		Role:   role,
+	})
+
+	ticketID, err := i.threadTicketID(senderCtx, email)
+	if err != nil {
+		log.Error("Failed to thread inbound message: ", err)
+		return nil, inboundError(err)
+	}
+
+	message := &model.InboundMessage{
+		MessageID: email.MessageID,
+		UserID:    sender.ID,
+		Subject:   truncate(email.Subject, maxInboundSubjectLength),
+		CreatedAt: time.Now(),
+	}
+
+	err = i.unitOfWork.Do(senderCtx, func(ctx context.Context) error {
+		if ticketID != 0 {
+			comment, err := i.commentUsecase.Create(ctx, model.CreateCommentInput{
+				TicketId: ticketID,
+				Content:  orPlaceholder(helper.StripQuotedReply(email.Text)),
+			})
+			if err != nil {
+				return err
+			}
+
+			message.TicketID = ticketID
+			message.CommentID = &comment.ID
+		} else {
+			ticket, err := i.ticketUsecase.Create(ctx, model.CreateTicketInput{
+				Title:       orPlaceholder(truncate(email.Subject, maxInboundSubjectLength)),
+				Description: orPlaceholder(email.Text),
+				Priority:    config.InboundDefaultPriority(),
+				AssignedTo:  config.InboundDefaultAssignee(),
+				Queue:       config.InboundQueue(),
+			})
+			if err != nil {
+				return err
+			}
+
+			message.TicketID = ticket.ID
+		}
+
+		for _, attachment := range email.Attachments {
+			err := i.saveAttachment(ctx, message.TicketID, attachment)
+			if err != nil {
+				return err
+			}
+		}
+
+		return i.inboundMessageRepo.Create(ctx, message)
+	})
+	if err != nil {
+		log.Error("Failed to process inbound message: ", err)
+		return nil, inboundError(err)
+	}
+
+	log.WithField("ticket_id", message.TicketID).Info("Processed inbound message")
+	return message, nil
+}
+
+// inboundError wraps err in ErrInboundRejected when it comes from the
+// content of the message rather than from a service that was unavailable.
+func inboundError(err error) error {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) ||
+		errors.Is(err, model.ErrForbidden) ||
+		errors.Is(err, model.ErrTicketNotFound) ||
+		errors.Is(err, model.ErrInvalidTransition) {
+		return fmt.Errorf("%w: %s", model.ErrInboundRejected, err)
+	}
+
+	return err
+}
+
+// findOrCreateSender returns the user with the sender's address, creating a
+// customer account with a random password for new senders.
+func (i *InboundEmailUsecase) findOrCreateSender(ctx context.Context, email *model.InboundEmail) (*model.User, error) {
+	user := i.userRepo.FindByEmail(ctx, email.From)
+	if user != nil {
+		return user, nil
+	}
+
+	password, err := helper.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordHashed, err := helper.HashRequestPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	name := email.FromName
+	if name == "" {
+		name = strings.SplitN(email.From, "@", 2)[0]
+	}
+
+	return i.userRepo.Create(ctx, model.User{
+		Name:     name,
+		Email:    email.From,
+		Password: passwordHashed,
+		Role:     model.RoleCustomer,
+	})
+}
+
+// threadTicketID finds the ticket an email replies to from its In-Reply-To
+// and References headers, then from a "[#123]" token in the subject. A ticket
+// the sender cannot see is ignored, so the email opens a new ticket instead.
+func (i *InboundEmailUsecase) threadTicketID(ctx context.Context, email *model.InboundEmail) (int64, error) {
+	var candidates []int64
+
+	references := email.References
+	if email.InReplyTo != "" {
+		references = append([]string{email.InReplyTo}, references...)
+	}
+
+	for _, reference := range references {
+		if id := helper.TicketIDFromMessageID(reference); id != 0 {
+			candidates = append(candidates, id)
+		}
+	}
+
+	ticketID, err := i.inboundMessageRepo.FindTicketIDByMessageIDs(ctx, references)
+	if err != nil {
+		return 0, err
+	}
+	if ticketID != 0 {
+		candidates = append(candidates, ticketID)
+	}
+
+	if id := helper.TicketIDFromSubject(email.Subject); id != 0 {
+		candidates = append(candidates, id)
+	}
+
+	for _, id := range candidates {
+		_, err := i.ticketUsecase.FindById(ctx, id)
+		if err == nil {
+			return id, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// saveAttachment stores the attachment and records it on the ticket. Files
+// that are too large or of a type that is not allowed are dropped, so the
+// rest of the email still goes through.
+func (i *InboundEmailUsecase) saveAttachment(ctx context.Context, ticketID int64, attachment model.InboundAttachment) error {
+	_, err := i.attachmentUsecase.Upload(ctx, model.UploadAttachmentInput{
+		TicketID:    ticketID,
+		Filename:    truncate(attachment.Filename, 255),
+		ContentType: attachment.ContentType,
+		Size:        int64(len(attachment.Content)),
+		Content:     bytes.NewReader(attachment.Content),
+	})
+	if errors.Is(err, model.ErrFileTooLarge) || errors.Is(err, model.ErrFileTypeNotAllowed) {
+		logrus.WithFields(logrus.Fields{
+			"ticket_id": ticketID,
+			"filename":  attachment.Filename,
+		}).Warn("Dropping inbound attachment: ", err)
+		return nil
+	}
+
+	return err
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+
+	return string(runes[:max])
+}
+
+func orPlaceholder(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "(no content)"
+	}
+
+	return s
+}
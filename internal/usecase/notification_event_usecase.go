@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NotifyTicketEvent sends the assignee new and reassigned tickets, and the
+// requester and assignee status changes and comments. The creator of a
+// ticket assigned to themselves is still told about it. Infected
+// attachments are reported to their uploader and every admin, and SLA
+// breach warnings to the assignee.
+func (n *NotificationUsecase) NotifyTicketEvent(ctx context.Context, event model.DomainEvent) error {
+	ticket := event.EventTicket()
+	actorID := event.EventMeta().ActorID
+
+	log := logrus.WithFields(logrus.Fields{
+		"event":     event.EventName(),
+		"ticket_id": ticket.ID,
+	})
+
+	var actorName string
+	if actorID != 0 {
+		actor, err := n.userRepo.FindById(ctx, actorID)
+		if err == nil {
+			actorName = actor.Name
+		}
+	}
+
+	notification := model.Notification{
+		Subject:   ticket.Title,
+		Message:   ticket.Description,
+		Data:      ticketNotificationData(ticket, actorName),
+		Status:    model.NotificationStatusPending,
+		TicketID:  ticket.ID,
+		CreatedAt: time.Now(),
+	}
+
+	var recipients []int64
+	switch e := event.(type) {
+	case *model.TicketCreated:
+		notification.Event = model.NotificationEventTicketCreated
+		recipients = []int64{ticket.AssignedTo}
+		actorID = 0
+	case *model.TicketAssigned:
+		notification.Event = model.NotificationEventTicketAssigned
+		recipients = []int64{ticket.AssignedTo}
+	case *model.TicketStatusChanged:
+		notification.Event = model.NotificationEventTicketStatusChanged
+		notification.Message = fmt.Sprintf("Status changed from %s to %s", e.From, e.To)
+		notification.Data.PreviousStatus = e.From
+		recipients = []int64{ticket.UserID, ticket.AssignedTo}
+	case *model.CommentAdded:
+		notification.Event = model.NotificationEventTicketCommented
+		notification.Message = e.Comment.Content
+		notification.Data.Comment = e.Comment.Content
+		recipients = []int64{ticket.UserID, ticket.AssignedTo}
+	case *model.TicketSLAAtRisk:
+		notification.Event = model.NotificationEventSLABreachWarning
+		notification.Data.DueBy = &e.DueBy
+		notification.Data.SLATarget = e.Target
+		recipients = []int64{ticket.AssignedTo}
+	case *model.AttachmentInfected:
+		notification.Event = model.NotificationEventAttachmentInfected
+		notification.Message = fmt.Sprintf("Malware found in attachment %s: %s", e.Attachment.Filename, e.Attachment.ScanSignature)
+		notification.Data.Attachment = e.Attachment.Filename
+		notification.Data.Signature = e.Attachment.ScanSignature
+
+		admins, err := n.userRepo.FindAll(ctx, model.User{Role: model.RoleAdmin})
+		if err != nil {
+			log.Error("Failed to fetch admins: ", err)
+			return err
+		}
+
+		recipients = []int64{e.Attachment.UploadedBy}
+		for _, admin := range admins {
+			recipients = append(recipients, admin.ID)
+		}
+	default:
+		return nil
+	}
+
+	seen := make(map[int64]bool)
+	for _, userID := range recipients {
+		if userID == 0 || userID == actorID || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		user, err := n.userRepo.FindById(ctx, userID)
+		if err != nil {
+			log.Error("Failed to fetch recipient: ", err)
+			return err
+		}
+
+		err = n.NotifyUser(ctx, user, notification)
+		if err != nil {
+			log.Error("Failed to send notification: ", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ticketNotificationData(ticket *model.Ticket, actorName string) model.NotificationData {
+	return model.NotificationData{
+		TicketID:    ticket.ID,
+		TicketTitle: ticket.Title,
+		TicketURL:   helper.TicketURL(ticket.ID),
+		Description: ticket.Description,
+		Priority:    ticket.Priority,
+		Status:      ticket.Status,
+		DueBy:       ticket.DueBy,
+		ActorName:   actorName,
+	}
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// saveInAppNotification stores the inbox copy of a notification. It is never
+// queued or held, since showing it in the inbox does not disturb anyone.
+func (n *NotificationUsecase) saveInAppNotification(ctx context.Context, user *model.User, notification model.Notification) error {
+	sentAt := time.Now()
+
+	notification.UserID = user.ID
+	notification.Email = user.Email
+	notification.Channel = model.NotificationChannelInApp
+	notification.Target = ""
+	notification.Status = model.NotificationStatusSent
+	notification.SentAt = &sentAt
+
+	err := n.notificationRepo.Save(ctx, &notification)
+	if err != nil {
+		logrus.Error("Failed to save in-app notification: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (n *NotificationUsecase) FindInbox(ctx context.Context, param model.NotificationInboxParam) (*model.NotificationPage, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"param": param,
+	})
+
+	err := helper.Validator.Struct(param)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, fmt.Errorf("%w: %s", model.ErrInvalidFilter, err)
+	}
+
+	if param.Cursor != "" {
+		_, _, err = helper.DecodeCursor(param.Cursor)
+		if err != nil {
+			log.Error("Validation error: ", err)
+			return nil, fmt.Errorf("%w: %s", model.ErrInvalidFilter, err)
+		}
+	}
+
+	userID, err := helper.GetUserID(ctx)
+	if err != nil {
+		log.Error("Failed to get user ID: ", err)
+		return nil, err
+	}
+
+	page, err := n.notificationRepo.FindInbox(ctx, userID, param)
+	if err != nil {
+		log.Error("Failed to fetch notifications: ", err)
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (n *NotificationUsecase) MarkRead(ctx context.Context, id int64) error {
+	log := logrus.WithFields(logrus.Fields{
+		"id": id,
+	})
+
+	userID, err := helper.GetUserID(ctx)
+	if err != nil {
+		log.Error("Failed to get user ID: ", err)
+		return err
+	}
+
+	notification, err := n.notificationRepo.FindById(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch notification: ", err)
+		return err
+	}
+
+	if notification.UserID != userID || notification.Channel != model.NotificationChannelInApp {
+		log.Error("Notification is not in the caller's inbox")
+		return model.ErrForbidden
+	}
+
+	err = n.notificationRepo.MarkRead(ctx, id)
+	if err != nil {
+		log.Error("Failed to mark notification read: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (n *NotificationUsecase) MarkAllRead(ctx context.Context) (int64, error) {
+	userID, err := helper.GetUserID(ctx)
+	if err != nil {
+		logrus.Error("Failed to get user ID: ", err)
+		return 0, err
+	}
+
+	count, err := n.notificationRepo.MarkAllRead(ctx, userID)
+	if err != nil {
+		logrus.Error("Failed to mark notifications read: ", err)
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,369 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultDigestHour is the local hour daily digests go out at until the user
+// picks another.
+const defaultDigestHour = 8
+
+func (n *NotificationUsecase) GetPreferences(ctx context.Context) (*model.NotificationPreferences, error) {
+	userID, err := helper.GetUserID(ctx)
+	if err != nil {
+		logrus.Error("Failed to get user ID: ", err)
+		return nil, err
+	}
+
+	return n.findPreferences(ctx, userID)
+}
+
+func (n *NotificationUsecase) UpdatePreferences(ctx context.Context, in model.UpdateNotificationPreferencesInput) (*model.NotificationPreferences, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"input": in,
+	})
+
+	err := helper.Validator.Struct(in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	userID, err := helper.GetUserID(ctx)
+	if err != nil {
+		log.Error("Failed to get user ID: ", err)
+		return nil, err
+	}
+
+	if in.Timezone == "" {
+		in.Timezone = "UTC"
+	}
+	if in.DigestMode == "" {
+		in.DigestMode = model.NotificationDigestOff
+	}
+
+	digestHour := defaultDigestHour
+	if in.DigestHour != nil {
+		digestHour = *in.DigestHour
+	} else {
+		current, err := n.notificationPreferenceRepo.FindSettings(ctx, userID)
+		if err != nil {
+			log.Error("Failed to fetch notification settings: ", err)
+			return nil, err
+		}
+		if current != nil {
+			digestHour = current.DigestHour
+		}
+	}
+
+	seen := make(map[string]bool)
+	preferences := make([]model.NotificationPreference, 0, len(in.Preferences))
+	for _, preference := range in.Preferences {
+		key := preference.Event + "/" + preference.Channel
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate preference for %s on %s", preference.Event, preference.Channel)
+		}
+		seen[key] = true
+
+		preferences = append(preferences, model.NotificationPreference{
+			UserID:    userID,
+			Event:     preference.Event,
+			Channel:   preference.Channel,
+			Enabled:   preference.Enabled,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+	}
+
+	err = n.notificationPreferenceRepo.Save(ctx, model.NotificationSettings{
+		UserID:          userID,
+		Timezone:        in.Timezone,
+		QuietHoursStart: in.QuietHoursStart,
+		QuietHoursEnd:   in.QuietHoursEnd,
+		DigestMode:      in.DigestMode,
+		DigestHour:      digestHour,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}, preferences)
+	if err != nil {
+		log.Error("Failed to save notification preferences: ", err)
+		return nil, err
+	}
+
+	return n.findPreferences(ctx, userID)
+}
+
+func (n *NotificationUsecase) findPreferences(ctx context.Context, userID int64) (*model.NotificationPreferences, error) {
+	settings, err := n.notificationPreferenceRepo.FindSettings(ctx, userID)
+	if err != nil {
+		logrus.Error("Failed to fetch notification settings: ", err)
+		return nil, err
+	}
+
+	if settings == nil {
+		settings = &model.NotificationSettings{
+			UserID:     userID,
+			Timezone:   "UTC",
+			DigestMode: model.NotificationDigestOff,
+			DigestHour: defaultDigestHour,
+		}
+	}
+
+	preferences, err := n.notificationPreferenceRepo.FindAllByUserID(ctx, userID)
+	if err != nil {
+		logrus.Error("Failed to fetch notification preferences: ", err)
+		return nil, err
+	}
+
+	return &model.NotificationPreferences{
+		Settings:    settings,
+		Preferences: preferences,
+	}, nil
+}
+
+// deliveryRules returns the channels the user turned the event off for and,
+// when the notification is not urgent, the time it is held until: their next
+// digest, or the end of their quiet hours.
+func (n *NotificationUsecase) deliveryRules(ctx context.Context, userID int64, notification model.Notification) (map[string]bool, *time.Time, error) {
+	preferences, err := n.notificationPreferenceRepo.FindAllByUserID(ctx, userID)
+	if err != nil {
+		logrus.Error("Failed to fetch notification preferences: ", err)
+		return nil, nil, err
+	}
+
+	disabled := make(map[string]bool)
+	for _, preference := range preferences {
+		if preference.Event == notification.Event && !preference.Enabled {
+			disabled[preference.Channel] = true
+		}
+	}
+
+	if isUrgentNotification(notification) {
+		return disabled, nil, nil
+	}
+
+	settings, err := n.notificationPreferenceRepo.FindSettings(ctx, userID)
+	if err != nil {
+		logrus.Error("Failed to fetch notification settings: ", err)
+		return nil, nil, err
+	}
+
+	if next := helper.NextDigestAt(time.Now(), settings); next != nil {
+		return disabled, next, nil
+	}
+
+	return disabled, helper.QuietHoursEnd(time.Now(), settings), nil
+}
+
+// isUrgentNotification reports whether the notification goes out even
+// during quiet hours.
+func isUrgentNotification(notification model.Notification) bool {
+	return notification.Data.Priority == "high" ||
+		notification.Event == model.NotificationEventSLABreachWarning ||
+		notification.Event == model.NotificationEventAttachmentInfected
+}
+
+// SendDigests sends one digest per recipient and channel for the held
+// notifications that are due. It returns the number of digests sent.
+func (n *NotificationUsecase) SendDigests(ctx context.Context) (int, error) {
+	held, err := n.notificationRepo.FindHeldDue(ctx, time.Now())
+	if err != nil {
+		logrus.Error("Failed to fetch held notifications: ", err)
+		return 0, err
+	}
+
+	sent := 0
+	for start := 0; start < len(held); {
+		end := start + 1
+		for end < len(held) && sameRecipient(held[start], held[end]) {
+			end++
+		}
+
+		ok, err := n.sendDigest(ctx, held[start:end])
+		if err != nil {
+			return sent, err
+		}
+
+		if ok {
+			sent++
+		}
+		start = end
+	}
+
+	return sent, nil
+}
+
+func sameRecipient(a *model.Notification, b *model.Notification) bool {
+	return a.UserID == b.UserID && a.Channel == b.Channel && a.Target == b.Target
+}
+
+// sendDigest sends the notifications of one recipient as a single digest and
+// records the digest on each of them in the same transaction. The rows are
+// locked first, so when several digest runs overlap each notification goes
+// out in one digest only; it reports false when another run had them all.
+func (n *NotificationUsecase) sendDigest(ctx context.Context, held []*model.Notification) (bool, error) {
+	ids := make([]int64, 0, len(held))
+	for _, notification := range held {
+		ids = append(ids, notification.ID)
+	}
+
+	sent := false
+	err := n.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		notifications, err := n.notificationRepo.LockHeld(ctx, ids)
+		if err != nil {
+			logrus.Error("Failed to lock held notifications: ", err)
+			return err
+		}
+
+		if len(notifications) == 0 {
+			return nil
+		}
+
+		err = n.sendDigestOf(ctx, notifications)
+		if err != nil {
+			return err
+		}
+
+		sent = true
+		return nil
+	})
+
+	return sent, err
+}
+
+func (n *NotificationUsecase) sendDigestOf(ctx context.Context, notifications []*model.Notification) error {
+	first := notifications[0]
+
+	ids := make([]int64, 0, len(notifications))
+	for _, notification := range notifications {
+		ids = append(ids, notification.ID)
+	}
+
+	summary := buildDigest(notifications, n.currentTickets(ctx, notifications), time.Now())
+
+	digest := model.Notification{
+		UserID:    first.UserID,
+		Email:     first.Email,
+		Channel:   first.Channel,
+		Target:    first.Target,
+		Subject:   fmt.Sprintf("Digest: %d new, %d updated, %d overdue tickets", summary.New, summary.Updated, summary.Overdue),
+		Message:   fmt.Sprintf("%d notifications about %d tickets since your last digest.", len(notifications), len(summary.Tickets)),
+		Event:     model.NotificationEventDigest,
+		Data:      model.NotificationData{Digest: summary},
+		Status:    model.NotificationStatusPending,
+		CreatedAt: time.Now(),
+	}
+
+	err := n.SendNotification(ctx, &digest)
+	if err != nil {
+		logrus.Error("Failed to send digest: ", err)
+		return err
+	}
+
+	err = n.notificationRepo.MarkDigested(ctx, ids, digest.ID)
+	if err != nil {
+		logrus.Error("Failed to mark notifications digested: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// currentTickets loads the tickets the notifications are about. Tickets that
+// cannot be loaded are left out, and the digest falls back to the state
+// recorded on their notifications.
+func (n *NotificationUsecase) currentTickets(ctx context.Context, notifications []*model.Notification) map[int64]*model.Ticket {
+	tickets := make(map[int64]*model.Ticket)
+	for _, notification := range notifications {
+		if notification.TicketID == 0 {
+			continue
+		}
+		if _, ok := tickets[notification.TicketID]; ok {
+			continue
+		}
+
+		ticket, err := n.ticketRepo.FindById(ctx, notification.TicketID)
+		if err != nil {
+			logrus.WithField("ticket_id", notification.TicketID).Warn("Failed to fetch ticket for digest: ", err)
+		}
+		tickets[notification.TicketID] = ticket
+	}
+
+	return tickets
+}
+
+// buildDigest groups the notifications by ticket in the order they were
+// first seen. The ticket state comes from tickets when the ticket is there,
+// and otherwise from the latest notification about it. Only open and
+// in-progress tickets count as overdue: pending tickets have their SLA
+// clock stopped.
+func buildDigest(notifications []*model.Notification, tickets map[int64]*model.Ticket, now time.Time) *model.NotificationDigest {
+	digest := &model.NotificationDigest{}
+	positions := make(map[int64]int)
+
+	for _, notification := range notifications {
+		position, ok := positions[notification.TicketID]
+		if !ok {
+			position = len(digest.Tickets)
+			positions[notification.TicketID] = position
+			digest.Tickets = append(digest.Tickets, model.NotificationDigestTicket{TicketID: notification.TicketID})
+		}
+
+		ticket := &digest.Tickets[position]
+		data := notification.Data
+		if data.TicketTitle != "" {
+			ticket.TicketTitle = data.TicketTitle
+		}
+		if data.TicketURL != "" {
+			ticket.TicketURL = data.TicketURL
+		}
+		if data.Priority != "" {
+			ticket.Priority = data.Priority
+		}
+		if data.Status != "" {
+			ticket.Status = data.Status
+		}
+		if data.DueBy != nil {
+			ticket.DueBy = data.DueBy
+		}
+		if notification.Event == model.NotificationEventTicketCreated {
+			ticket.New = true
+		}
+
+		ticket.Items = append(ticket.Items, model.NotificationDigestItem{
+			Event:     notification.Event,
+			Subject:   notification.Subject,
+			CreatedAt: notification.CreatedAt,
+		})
+	}
+
+	for i := range digest.Tickets {
+		ticket := &digest.Tickets[i]
+		if current := tickets[ticket.TicketID]; current != nil && current.DeletedAt == nil {
+			ticket.TicketTitle = current.Title
+			ticket.Priority = current.Priority
+			ticket.Status = current.Status
+			ticket.DueBy = current.DueBy
+		}
+
+		ticket.Overdue = ticket.DueBy != nil && ticket.DueBy.Before(now) &&
+			(ticket.Status == model.TicketStatusOpen || ticket.Status == model.TicketStatusInProgress)
+
+		if ticket.New {
+			digest.New++
+		} else {
+			digest.Updated++
+		}
+		if ticket.Overdue {
+			digest.Overdue++
+		}
+	}
+
+	return digest
+}
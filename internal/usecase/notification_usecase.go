@@ -3,55 +3,465 @@ package usecase
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/helper"
 	"helpdesk-ticketing-system/internal/model"
+	"strings"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/sirupsen/logrus"
 )
 
+const defaultRequeueLimit = 100
+
 type NotificationUsecase struct {
-	notificationRepo model.INotificationRepository
-	rmq              *amqp.Channel
+	notificationRepo           model.INotificationRepository
+	notificationChannelRepo    model.INotificationChannelRepository
+	notificationPreferenceRepo model.INotificationPreferenceRepository
+	userRepo                   model.IUserRepository
+	ticketRepo                 model.ITicketRepository
+	outboxUsecase              model.IOutboxUsecase
+	unitOfWork                 model.IUnitOfWork
+	emailTemplates             *helper.EmailTemplates
+	rmq                        *amqp.Connection
 }
 
-func NewNotificationUsecase(notificationRepo model.INotificationRepository, rmq *amqp.Channel) model.INotificationUsecase {
+func NewNotificationUsecase(
+	notificationRepo model.INotificationRepository,
+	notificationChannelRepo model.INotificationChannelRepository,
+	notificationPreferenceRepo model.INotificationPreferenceRepository,
+	userRepo model.IUserRepository,
+	ticketRepo model.ITicketRepository,
+	outboxUsecase model.IOutboxUsecase,
+	unitOfWork model.IUnitOfWork,
+	emailTemplates *helper.EmailTemplates,
+	rmq *amqp.Connection,
+) model.INotificationUsecase {
 	return &NotificationUsecase{
-		notificationRepo: notificationRepo,
-		rmq:              rmq,
+		notificationRepo:           notificationRepo,
+		notificationChannelRepo:    notificationChannelRepo,
+		notificationPreferenceRepo: notificationPreferenceRepo,
+		userRepo:                   userRepo,
+		ticketRepo:                 ticketRepo,
+		outboxUsecase:              outboxUsecase,
+		unitOfWork:                 unitOfWork,
+		emailTemplates:             emailTemplates,
+		rmq:                        rmq,
 	}
 }
 
+// SendNotification saves the notification and queues it for the worker of its
+// channel through the outbox, in one transaction that joins the caller's unit
+// of work.
 func (n *NotificationUsecase) SendNotification(ctx context.Context, notification *model.Notification) error {
 	log := logrus.WithFields(logrus.Fields{
 		"notification": notification,
 	})
 
-	body, err := json.Marshal(notification)
+	if notification.Event == "" {
+		notification.Event = model.NotificationEventGeneric
+	}
+	if notification.Channel == "" {
+		notification.Channel = model.NotificationChannelEmail
+	}
+
+	err := helper.Validator.Var(notification.Channel, "oneof=email webhook chat")
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return err
+	}
+
+	// no DNS lookup here: a target that stopped resolving must not fail the
+	// change that raised the notification, and the drivers refuse private
+	// addresses when they connect
+	if notification.Channel != model.NotificationChannelEmail {
+		err = checkChannelTargetFormat(notification.Channel, notification.Target)
+		if err != nil {
+			log.Error("Validation error: ", err)
+			return err
+		}
+	}
+
+	return n.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		err := n.notificationRepo.Save(ctx, notification)
+		if err != nil {
+			log.Error("Failed to save notification: ", err)
+			return err
+		}
+
+		err = n.outboxUsecase.Enqueue(ctx, config.NotificationExchange, config.NotificationQueue(notification.Channel), notification)
+		if err != nil {
+			log.Error("Failed to queue notification: ", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// NotifyUser stores the notification in the user's inbox and sends a copy to
+// every channel the user has set up, or to their account email when they have
+// none. Channels the user turned the event off for are skipped, and during
+// their quiet hours the copies are held for a digest unless the notification
+// is urgent.
+func (n *NotificationUsecase) NotifyUser(ctx context.Context, user *model.User, notification model.Notification) error {
+	settings, err := n.notificationChannelRepo.FindAllByUserID(ctx, user.ID)
+	if err != nil {
+		logrus.Error("Failed to fetch notification channels: ", err)
+		return err
+	}
+
+	if len(settings) == 0 {
+		settings = []*model.NotificationChannelSetting{{Channel: model.NotificationChannelEmail}}
+	}
+
+	disabled, heldUntil, err := n.deliveryRules(ctx, user.ID, notification)
+	if err != nil {
+		return err
+	}
+
+	return n.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		if !disabled[model.NotificationChannelInApp] {
+			err := n.saveInAppNotification(ctx, user, notification)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, setting := range settings {
+			channelNotification := notification
+			channelNotification.UserID = user.ID
+			channelNotification.Email = user.Email
+			channelNotification.Channel = setting.Channel
+			channelNotification.Target = setting.Target
+
+			if setting.Channel == model.NotificationChannelEmail && setting.Target != "" {
+				channelNotification.Email = setting.Target
+			}
+
+			if disabled[setting.Channel] {
+				continue
+			}
+
+			if heldUntil != nil {
+				channelNotification.Status = model.NotificationStatusHeld
+				channelNotification.HeldUntil = heldUntil
+
+				err := n.notificationRepo.Save(ctx, &channelNotification)
+				if err != nil {
+					logrus.Error("Failed to hold notification: ", err)
+					return err
+				}
+				continue
+			}
+
+			err := n.SendNotification(ctx, &channelNotification)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (n *NotificationUsecase) FindChannels(ctx context.Context) ([]*model.NotificationChannelSetting, error) {
+	userID, err := helper.GetUserID(ctx)
+	if err != nil {
+		logrus.Error("Failed to get user ID: ", err)
+		return nil, err
+	}
+
+	settings, err := n.notificationChannelRepo.FindAllByUserID(ctx, userID)
+	if err != nil {
+		logrus.Error("Failed to fetch notification channels: ", err)
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (n *NotificationUsecase) CreateChannel(ctx context.Context, in model.CreateNotificationChannelInput) (*model.NotificationChannelSetting, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"input": in,
+	})
+
+	err := helper.Validator.Struct(in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	err = validateChannelTarget(ctx, in.Channel, in.Target)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	userID, err := helper.GetUserID(ctx)
+	if err != nil {
+		log.Error("Failed to get user ID: ", err)
+		return nil, err
+	}
+
+	setting, err := n.notificationChannelRepo.Create(ctx, model.NotificationChannelSetting{
+		UserID:    userID,
+		Channel:   in.Channel,
+		Target:    in.Target,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
 	if err != nil {
-		log.Error("Failed to marshal notification: ", err)
+		log.Error("Failed to create notification channel: ", err)
+		return nil, err
+	}
+
+	return setting, nil
+}
+
+func (n *NotificationUsecase) DeleteChannel(ctx context.Context, id int64) error {
+	log := logrus.WithFields(logrus.Fields{
+		"id": id,
+	})
+
+	userID, err := helper.GetUserID(ctx)
+	if err != nil {
+		log.Error("Failed to get user ID: ", err)
 		return err
 	}
 
-	err = n.rmq.Publish(
-		"notification", // if empty, use default exchange
-		"emailQueue",   // if empty, use default routing key
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		},
-	)
+	setting, err := n.notificationChannelRepo.FindById(ctx, id)
 	if err != nil {
-		log.Error("Failed to publish notification: ", err)
+		log.Error("Failed to fetch notification channel: ", err)
 		return err
 	}
 
-	err = n.notificationRepo.Save(ctx, notification)
+	if setting.UserID != userID {
+		log.Error("Notification channel belongs to another user")
+		return model.ErrForbidden
+	}
+
+	err = n.notificationChannelRepo.Delete(ctx, id)
 	if err != nil {
-		log.Error("Failed to save notification: ", err)
+		log.Error("Failed to delete notification channel: ", err)
 		return err
 	}
 
 	return nil
 }
+
+// validateChannelTarget checks the target of a new channel: an optional
+// address for email and an http(s) URL on a public host for the webhook
+// channels. The drivers check the address again when they connect.
+func validateChannelTarget(ctx context.Context, channel string, target string) error {
+	err := checkChannelTargetFormat(channel, target)
+	if err != nil || channel == model.NotificationChannelEmail {
+		return err
+	}
+
+	return helper.CheckPublicURL(ctx, target)
+}
+
+// checkChannelTargetFormat is validateChannelTarget without resolving the
+// host.
+func checkChannelTargetFormat(channel string, target string) error {
+	if channel == model.NotificationChannelEmail {
+		if target == "" {
+			return nil
+		}
+		return helper.Validator.Var(target, "email")
+	}
+
+	err := helper.Validator.Var(target, "required,url")
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(target, "https://") && !strings.HasPrefix(target, "http://") {
+		return errors.New("webhook target must be an http or https URL")
+	}
+
+	return nil
+}
+
+func (n *NotificationUsecase) RecordDelivery(ctx context.Context, id int64, delivery model.NotificationDelivery) error {
+	err := n.notificationRepo.UpdateDelivery(ctx, id, delivery)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"id":       id,
+			"delivery": delivery,
+		}).Error("Failed to record notification delivery: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// RequeueDeadLetters moves up to in.Limit messages from the dead-letter queues
+// back onto their channel queues with a fresh set of retries.
+func (n *NotificationUsecase) RequeueDeadLetters(ctx context.Context, in model.RequeueDeadLettersInput) (int, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"input": in,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can requeue dead letters")
+		return 0, model.ErrForbidden
+	}
+
+	err := helper.Validator.Struct(in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return 0, err
+	}
+
+	if in.Limit == 0 {
+		in.Limit = defaultRequeueLimit
+	}
+
+	queues := config.NotificationQueues
+	if in.Channel != "" {
+		queues = []string{config.NotificationQueue(in.Channel)}
+	}
+
+	// basic.get on the channel the workers consume from would interleave
+	// with their acks; a channel of its own also hands unacked messages back
+	// to the dead-letter queue when it is closed early
+	ch, err := n.rmq.Channel()
+	if err != nil {
+		log.Error("Failed to open RabbitMQ channel: ", err)
+		return 0, err
+	}
+	defer ch.Close()
+
+	requeued := 0
+	for _, queue := range queues {
+		count, err := n.requeueDeadLetters(ctx, ch, queue, in.Limit-requeued)
+		requeued += count
+		if err != nil {
+			log.Error("Failed to requeue dead letters: ", err)
+			return requeued, err
+		}
+	}
+
+	log.Infof("Requeued %d dead letters", requeued)
+	return requeued, nil
+}
+
+func (n *NotificationUsecase) RenderEmail(notification *model.Notification) (*model.RenderedEmail, error) {
+	email, err := n.emailTemplates.Render(notification)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"id":    notification.ID,
+			"event": notification.Event,
+		}).Error("Failed to render email: ", err)
+		return nil, err
+	}
+
+	return email, nil
+}
+
+// PreviewTemplate renders an event with the given data, or with a sample
+// ticket when no data is sent.
+func (n *NotificationUsecase) PreviewTemplate(ctx context.Context, in model.PreviewTemplateInput) (*model.RenderedEmail, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"input": in,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can preview email templates")
+		return nil, model.ErrForbidden
+	}
+
+	err := helper.Validator.Struct(in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	data := in.Data
+	if data == nil {
+		dueBy := time.Now().Add(4 * time.Hour)
+		data = &model.NotificationData{
+			TicketID:       1024,
+			TicketTitle:    "Cannot log in to the customer portal",
+			TicketURL:      helper.TicketURL(1024),
+			Description:    "Since this morning the login page returns an error after submitting the form.",
+			Priority:       "high",
+			Status:         model.TicketStatusInProgress,
+			PreviousStatus: model.TicketStatusOpen,
+			DueBy:          &dueBy,
+			ActorName:      "Jane Agent",
+			Comment:        "I have reset your session, please try again.",
+		}
+
+		if in.Event == model.NotificationEventDigest {
+			data.Digest = buildDigest([]*model.Notification{
+				{TicketID: 1024, Event: model.NotificationEventTicketCreated, Subject: "New ticket #1024", Data: *data, CreatedAt: time.Now()},
+				{TicketID: 1024, Event: model.NotificationEventTicketCommented, Subject: "New comment on ticket #1024", Data: *data, CreatedAt: time.Now()},
+			}, nil, time.Now())
+		}
+	}
+
+	return n.RenderEmail(&model.Notification{
+		Subject: in.Subject,
+		Message: in.Message,
+		Event:   in.Event,
+		Data:    *data,
+	})
+}
+
+func (n *NotificationUsecase) requeueDeadLetters(ctx context.Context, ch *amqp.Channel, queue string, limit int) (int, error) {
+	requeued := 0
+	for requeued < limit {
+		delivery, ok, err := ch.Get(config.DeadLetterQueue(queue), false)
+		if err != nil {
+			return requeued, err
+		}
+		if !ok {
+			break
+		}
+
+		err = ch.PublishWithContext(
+			ctx,
+			config.NotificationExchange,
+			queue,
+			false,
+			false,
+			amqp.Publishing{
+				ContentType:  delivery.ContentType,
+				DeliveryMode: amqp.Persistent,
+				MessageId:    delivery.MessageId,
+				Headers:      amqp.Table{config.AttemptsHeader: int32(0)},
+				Body:         delivery.Body,
+			},
+		)
+		if err != nil {
+			delivery.Nack(false, true)
+			return requeued, err
+		}
+
+		err = delivery.Ack(false)
+		if err != nil {
+			return requeued, err
+		}
+
+		var notification model.Notification
+		if json.Unmarshal(delivery.Body, &notification) == nil && notification.ID > 0 {
+			// the message is already back on its queue; a stale status is
+			// overwritten once the worker has delivered it
+			err = n.RecordDelivery(ctx, notification.ID, model.NotificationDelivery{
+				Status: model.NotificationStatusPending,
+			})
+			if err != nil {
+				logrus.WithField("id", notification.ID).Warn("Requeued notification keeps its failed status")
+			}
+		}
+
+		requeued++
+	}
+
+	return requeued, nil
+}
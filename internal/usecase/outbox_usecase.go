@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/model"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+)
+
+const maxOutboxBackoff = time.Hour
+
+type OutboxUsecase struct {
+	outboxRepo model.IOutboxRepository
+	unitOfWork model.IUnitOfWork
+	rmq        *amqp.Channel
+}
+
+func NewOutboxUsecase(outboxRepo model.IOutboxRepository, unitOfWork model.IUnitOfWork, rmq *amqp.Channel) model.IOutboxUsecase {
+	return &OutboxUsecase{
+		outboxRepo: outboxRepo,
+		unitOfWork: unitOfWork,
+		rmq:        rmq,
+	}
+}
+
+// Enqueue stores the message in the outbox. Called inside a unit of work it
+// commits or rolls back together with the business change.
+func (o *OutboxUsecase) Enqueue(ctx context.Context, exchange string, routingKey string, payload interface{}) error {
+	log := logrus.WithFields(logrus.Fields{
+		"exchange":    exchange,
+		"routing_key": routingKey,
+	})
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Error("Failed to marshal outbox payload: ", err)
+		return err
+	}
+
+	_, err = o.outboxRepo.Create(ctx, model.OutboxMessage{
+		Exchange:    exchange,
+		RoutingKey:  routingKey,
+		ContentType: "application/json",
+		Payload:     body,
+		Status:      model.OutboxStatusPending,
+		AvailableAt: time.Now(),
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		log.Error("Failed to write outbox message: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// Relay publishes a batch of pending messages while holding their row locks,
+// so several relays can run side by side. A crash between publishing and
+// commit publishes the message again; consumers must tolerate duplicates.
+func (o *OutboxUsecase) Relay(ctx context.Context) (int, error) {
+	dispatched := 0
+
+	err := o.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		messages, err := o.outboxRepo.FindPending(ctx, config.OutboxBatchSize())
+		if err != nil {
+			logrus.Error("Failed to fetch pending outbox messages: ", err)
+			return err
+		}
+
+		for _, message := range messages {
+			log := logrus.WithFields(logrus.Fields{
+				"id":       message.ID,
+				"attempts": message.Attempts,
+			})
+
+			err = o.publish(ctx, message)
+			if err == nil {
+				err = o.outboxRepo.MarkDispatched(ctx, message.ID)
+				if err != nil {
+					log.Error("Failed to mark outbox message dispatched: ", err)
+					return err
+				}
+
+				dispatched++
+				continue
+			}
+
+			log.Warn("Failed to publish outbox message: ", err)
+
+			message.Attempts++
+			message.LastError = err.Error()
+			message.AvailableAt = time.Now().Add(outboxBackoff(message.Attempts))
+			if message.Attempts >= config.OutboxMaxAttempts() {
+				log.Error("Giving up on outbox message")
+				message.Status = model.OutboxStatusFailed
+			}
+
+			err = o.outboxRepo.MarkRetry(ctx, *message)
+			if err != nil {
+				log.Error("Failed to reschedule outbox message: ", err)
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return dispatched, nil
+}
+
+// publish waits for the broker to confirm the message when the channel is in
+// confirm mode.
+func (o *OutboxUsecase) publish(ctx context.Context, message *model.OutboxMessage) error {
+	confirmation, err := o.rmq.PublishWithDeferredConfirmWithContext(
+		ctx,
+		message.Exchange,
+		message.RoutingKey,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  message.ContentType,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    fmt.Sprintf("outbox-%d", message.ID),
+			Body:         message.Payload,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if confirmation == nil {
+		return nil
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return errors.New("message was nacked by the broker")
+	}
+
+	return nil
+}
+
+// outboxBackoff doubles the wait after every failed attempt, starting at one
+// second and capped at an hour.
+func outboxBackoff(attempts int) time.Duration {
+	if attempts > 12 {
+		return maxOutboxBackoff
+	}
+
+	backoff := time.Second << uint(attempts-1)
+	if backoff > maxOutboxBackoff {
+		return maxOutboxBackoff
+	}
+
+	return backoff
+}
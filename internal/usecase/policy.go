@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+)
+
+// canReadTicket reports whether the caller may see the ticket.
+// Customers only see the tickets they filed themselves.
+func canReadTicket(claims model.CustomClaims, ticket *model.Ticket) bool {
+	switch claims.Role {
+	case model.RoleAdmin, model.RoleSupport:
+		return true
+	case model.RoleCustomer:
+		return ticket.UserID == claims.UserID
+	}
+
+	return false
+}
+
+// canReadAllTickets reports whether the caller may see every ticket, and so
+// query across tickets.
+func canReadAllTickets(claims model.CustomClaims) bool {
+	return claims.Role == model.RoleAdmin || claims.Role == model.RoleSupport
+}
+
+// checkReadAllTickets returns ErrForbidden unless the caller may query
+// across tickets.
+func checkReadAllTickets(ctx context.Context) error {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !canReadAllTickets(claims) {
+		return model.ErrForbidden
+	}
+
+	return nil
+}
+
+// findReadableTicket loads the ticket if it exists and the caller can see
+// it.
+func findReadableTicket(ctx context.Context, ticketRepo model.ITicketRepository, id int64) (*model.Ticket, error) {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := ticketRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket == nil || (ticket.DeletedAt != nil && !ticket.DeletedAt.IsZero()) {
+		return nil, model.ErrTicketNotFound
+	}
+
+	if !canReadTicket(claims, ticket) {
+		return nil, model.ErrForbidden
+	}
+
+	return ticket, nil
+}
+
+// canUpdateTicket reports whether the caller may edit the ticket.
+// Support agents are limited to the tickets assigned to them.
+func canUpdateTicket(claims model.CustomClaims, ticket *model.Ticket) bool {
+	switch claims.Role {
+	case model.RoleAdmin:
+		return true
+	case model.RoleSupport:
+		return ticket.AssignedTo == claims.UserID
+	}
+
+	return false
+}
+
+func canDeleteTicket(claims model.CustomClaims) bool {
+	return claims.Role == model.RoleAdmin
+}
+
+// canDeleteComment lets authors delete their own comments and admins delete
+// any.
+func canDeleteComment(claims model.CustomClaims, comment *model.Comment) bool {
+	return claims.Role == model.RoleAdmin || comment.UserID == claims.UserID
+}
+
+func canManageUser(claims model.CustomClaims, userID int64) bool {
+	return claims.Role == model.RoleAdmin || claims.UserID == userID
+}
+
+// isAdmin is used where no claims are required, such as public registration.
+func isAdmin(ctx context.Context) bool {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		return false
+	}
+
+	return claims.Role == model.RoleAdmin
+}
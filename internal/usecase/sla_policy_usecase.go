@@ -0,0 +1,309 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type SLAPolicyUsecase struct {
+	slaPolicyRepo        model.ISLAPolicyRepository
+	businessCalendarRepo model.IBusinessCalendarRepository
+}
+
+func NewSLAPolicyUsecase(
+	slaPolicyRepo model.ISLAPolicyRepository,
+	businessCalendarRepo model.IBusinessCalendarRepository,
+) model.ISLAPolicyUsecase {
+	return &SLAPolicyUsecase{
+		slaPolicyRepo:        slaPolicyRepo,
+		businessCalendarRepo: businessCalendarRepo,
+	}
+}
+
+func (s *SLAPolicyUsecase) FindAll(ctx context.Context) ([]*model.SLAPolicy, error) {
+	if !isAdmin(ctx) {
+		logrus.Error("Only admins can manage SLA policies")
+		return nil, model.ErrForbidden
+	}
+
+	policies, err := s.slaPolicyRepo.FindAll(ctx)
+	if err != nil {
+		logrus.Error("Failed to fetch SLA policies: ", err)
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func (s *SLAPolicyUsecase) FindById(ctx context.Context, id int64) (*model.SLAPolicy, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"id": id,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage SLA policies")
+		return nil, model.ErrForbidden
+	}
+
+	policy, err := s.slaPolicyRepo.FindById(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch SLA policy by ID: ", err)
+		return nil, err
+	}
+
+	if policy == nil {
+		log.Error("SLA policy not found")
+		return nil, errors.New("sla policy not found")
+	}
+
+	return policy, nil
+}
+
+func (s *SLAPolicyUsecase) Create(ctx context.Context, in model.CreateSLAPolicyInput) (*model.SLAPolicy, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"input": in,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage SLA policies")
+		return nil, model.ErrForbidden
+	}
+
+	err := helper.Validator.Struct(in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	targets, err := buildSLATargets(in.Targets)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	policy, err := s.slaPolicyRepo.Create(ctx, model.SLAPolicy{
+		Name:               in.Name,
+		CustomerID:         in.CustomerID,
+		Category:           in.Category,
+		Queue:              in.Queue,
+		BusinessCalendarID: in.BusinessCalendarID,
+		Targets:            targets,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	})
+	if err != nil {
+		log.Error("Failed to create SLA policy: ", err)
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (s *SLAPolicyUsecase) Update(ctx context.Context, id int64, in model.UpdateSLAPolicyInput) (*model.SLAPolicy, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"id":    id,
+		"input": in,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage SLA policies")
+		return nil, model.ErrForbidden
+	}
+
+	err := helper.Validator.Struct(in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	targets, err := buildSLATargets(in.Targets)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	existingPolicy, err := s.slaPolicyRepo.FindById(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch SLA policy: ", err)
+		return nil, err
+	}
+
+	policy, err := s.slaPolicyRepo.Update(ctx, model.SLAPolicy{
+		ID:                 id,
+		Name:               in.Name,
+		CustomerID:         in.CustomerID,
+		Category:           in.Category,
+		Queue:              in.Queue,
+		BusinessCalendarID: in.BusinessCalendarID,
+		Targets:            targets,
+		CreatedAt:          existingPolicy.CreatedAt,
+		UpdatedAt:          time.Now(),
+	})
+	if err != nil {
+		log.Error("Failed to update SLA policy: ", err)
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (s *SLAPolicyUsecase) Delete(ctx context.Context, id int64) error {
+	log := logrus.WithFields(logrus.Fields{
+		"id": id,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage SLA policies")
+		return model.ErrForbidden
+	}
+
+	_, err := s.slaPolicyRepo.FindById(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch SLA policy: ", err)
+		return err
+	}
+
+	err = s.slaPolicyRepo.Delete(ctx, id)
+	if err != nil {
+		log.Error("Failed to delete SLA policy: ", err)
+		return err
+	}
+
+	log.Info("Successfully deleted SLA policy with ID: ", id)
+	return nil
+}
+
+// ApplyToTicket picks the SLA policy for the ticket and sets its due dates,
+// counting from the ticket creation time so later edits do not reset the clock.
+func (s *SLAPolicyUsecase) ApplyToTicket(ctx context.Context, ticket *model.Ticket) error {
+	policies, err := s.slaPolicyRepo.FindAll(ctx)
+	if err != nil {
+		logrus.Error("Failed to fetch SLA policies: ", err)
+		return err
+	}
+
+	firstResponse, resolution := helper.DefaultSLATargets(ticket.Priority)
+	ticket.SLAPolicyID = nil
+
+	// a matching policy without a target for the ticket's priority gives
+	// way to the next most specific one
+match:
+	for _, policy := range matchSLAPolicies(policies, ticket) {
+		for _, target := range policy.Targets {
+			if target.Priority != ticket.Priority {
+				continue
+			}
+
+			firstResponse = time.Duration(target.FirstResponseMinutes) * time.Minute
+			resolution = time.Duration(target.ResolutionMinutes) * time.Minute
+			ticket.SLAPolicyID = &policy.ID
+			break match
+		}
+	}
+
+	calendar, err := s.CalendarFor(ctx, ticket.SLAPolicyID)
+	if err != nil {
+		return err
+	}
+
+	start := ticket.CreatedAt
+	if start.IsZero() {
+		start = time.Now()
+	}
+
+	ticket.FirstResponseDueBy = helper.CalculateDueBy(start, firstResponse, calendar)
+	ticket.DueBy = helper.CalculateDueBy(start, resolution, calendar)
+
+	return nil
+}
+
+// CalendarFor returns the business calendar of the SLA policy, falling back
+// to the default calendar. A nil calendar means SLA time is not restricted
+// to business hours.
+func (s *SLAPolicyUsecase) CalendarFor(ctx context.Context, slaPolicyID *int64) (*model.BusinessCalendar, error) {
+	if slaPolicyID != nil {
+		policy, err := s.slaPolicyRepo.FindById(ctx, *slaPolicyID)
+		if err == nil && policy.BusinessCalendarID != nil {
+			calendar, err := s.businessCalendarRepo.FindById(ctx, *policy.BusinessCalendarID)
+			if err == nil {
+				return calendar, nil
+			}
+		}
+	}
+
+	calendar, err := s.businessCalendarRepo.FindDefault(ctx)
+	if err != nil {
+		logrus.Error("Failed to fetch default business calendar: ", err)
+		return nil, err
+	}
+
+	return calendar, nil
+}
+
+// matchSLAPolicies returns the policies whose criteria all match the ticket,
+// most specific first. A customer match outranks a queue match, which
+// outranks a category match; ties go to the oldest policy.
+func matchSLAPolicies(policies []*model.SLAPolicy, ticket *model.Ticket) []*model.SLAPolicy {
+	var matches []*model.SLAPolicy
+	scores := make(map[int64]int)
+
+	for _, policy := range policies {
+		score := 0
+
+		if policy.CustomerID != nil {
+			if *policy.CustomerID != ticket.UserID {
+				continue
+			}
+			score += 4
+		}
+
+		if policy.Queue != "" {
+			if policy.Queue != ticket.Queue {
+				continue
+			}
+			score += 2
+		}
+
+		if policy.Category != "" {
+			if policy.Category != ticket.Category {
+				continue
+			}
+			score++
+		}
+
+		matches = append(matches, policy)
+		scores[policy.ID] = score
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return scores[matches[i].ID] > scores[matches[j].ID]
+	})
+
+	return matches
+}
+
+func buildSLATargets(in []model.SLATargetInput) ([]model.SLATarget, error) {
+	seen := make(map[string]bool)
+	targets := make([]model.SLATarget, 0, len(in))
+
+	for _, target := range in {
+		if seen[target.Priority] {
+			return nil, errors.New("duplicate SLA target for priority " + target.Priority)
+		}
+		seen[target.Priority] = true
+
+		targets = append(targets, model.SLATarget{
+			Priority:             target.Priority,
+			FirstResponseMinutes: target.FirstResponseMinutes,
+			ResolutionMinutes:    target.ResolutionMinutes,
+		})
+	}
+
+	return targets, nil
+}
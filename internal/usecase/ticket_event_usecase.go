@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ticketEventBuffer      = 32
+	ticketEventResubscribe = time.Second
+)
+
+type ticketEventSubscriber struct {
+	claims   model.CustomClaims
+	ticketID int64
+}
+
+// TicketEventUsecase keeps one broker subscription per instance and fans
+// the events out to the clients connected to it.
+type TicketEventUsecase struct {
+	broker      model.ITicketEventBroker
+	start       sync.Once
+	mu          sync.Mutex
+	subscribers map[chan model.TicketEvent]ticketEventSubscriber
+}
+
+func NewTicketEventUsecase(broker model.ITicketEventBroker) model.ITicketEventUsecase {
+	return &TicketEventUsecase{
+		broker:      broker,
+		subscribers: make(map[chan model.TicketEvent]ticketEventSubscriber),
+	}
+}
+
+func (t *TicketEventUsecase) Publish(ctx context.Context, event model.DomainEvent) error {
+	ticketEvent, err := newTicketEvent(event)
+	if err != nil {
+		return err
+	}
+
+	return t.broker.Publish(ctx, ticketEvent)
+}
+
+// newTicketEvent flattens a domain event for clients and webhooks. Data is
+// the ticket, comment or attachment the event is about, or the event itself
+// when it carries more than that.
+func newTicketEvent(event model.DomainEvent) (model.TicketEvent, error) {
+	var data interface{} = event
+	switch e := event.(type) {
+	case *model.TicketCreated:
+		data = e.Ticket
+	case *model.TicketUpdated:
+		data = e.Ticket
+	case *model.CommentAdded:
+		data = e.Comment
+	case *model.AttachmentAdded:
+		data = e.Attachment
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return model.TicketEvent{}, err
+	}
+
+	ticket := event.EventTicket()
+	meta := event.EventMeta()
+
+	return model.TicketEvent{
+		ID:         meta.ID,
+		Type:       event.EventName(),
+		TicketID:   ticket.ID,
+		UserID:     ticket.UserID,
+		AssignedTo: ticket.AssignedTo,
+		Data:       payload,
+		OccurredAt: meta.OccurredAt,
+	}, nil
+}
+
+func (t *TicketEventUsecase) Subscribe(ctx context.Context, ticketID int64) (<-chan model.TicketEvent, error) {
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		logrus.Error("Failed to get claims: ", err)
+		return nil, err
+	}
+
+	t.start.Do(func() {
+		go t.run()
+	})
+
+	events := make(chan model.TicketEvent, ticketEventBuffer)
+
+	t.mu.Lock()
+	t.subscribers[events] = ticketEventSubscriber{claims: claims, ticketID: ticketID}
+	t.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		t.mu.Lock()
+		delete(t.subscribers, events)
+		close(events)
+		t.mu.Unlock()
+	}()
+
+	return events, nil
+}
+
+// run relays broker events to the subscribers for the life of the process,
+// subscribing again whenever the broker connection drops.
+func (t *TicketEventUsecase) run() {
+	for {
+		events, err := t.broker.Subscribe(context.Background())
+		if err != nil {
+			logrus.Error("Failed to subscribe to ticket events: ", err)
+			time.Sleep(ticketEventResubscribe)
+			continue
+		}
+
+		for event := range events {
+			t.dispatch(event)
+		}
+
+		time.Sleep(ticketEventResubscribe)
+	}
+}
+
+// dispatch hands the event to every subscriber that may read the ticket. A
+// subscriber whose buffer is full misses the event rather than holding up
+// everyone else.
+func (t *TicketEventUsecase) dispatch(event model.TicketEvent) {
+	ticket := &model.Ticket{ID: event.TicketID, UserID: event.UserID, AssignedTo: event.AssignedTo}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for events, subscriber := range t.subscribers {
+		if subscriber.ticketID != 0 && subscriber.ticketID != event.TicketID {
+			continue
+		}
+		if !canReadTicket(subscriber.claims, ticket) {
+			continue
+		}
+
+		select {
+		case events <- event:
+		default:
+			logrus.WithField("user_id", subscriber.claims.UserID).Warn("Dropped ticket event for slow subscriber")
+		}
+	}
+}
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"errors"
+	"helpdesk-ticketing-system/internal/helper"
 	"helpdesk-ticketing-system/internal/model"
 
 	"github.com/sirupsen/logrus"
@@ -10,10 +11,14 @@ import (
 
 type ticketHistoryUsecase struct {
 	ticketHistoryRepo model.ITicketHistoryRepository
+	ticketRepo        model.ITicketRepository
 }
 
-func NewTicketHistoryUsecase(ticketHistoryRepo model.ITicketHistoryRepository) model.ITicketHistoryUsecase {
-	return &ticketHistoryUsecase{ticketHistoryRepo: ticketHistoryRepo}
+func NewTicketHistoryUsecase(ticketHistoryRepo model.ITicketHistoryRepository, ticketRepo model.ITicketRepository) model.ITicketHistoryUsecase {
+	return &ticketHistoryUsecase{
+		ticketHistoryRepo: ticketHistoryRepo,
+		ticketRepo:        ticketRepo,
+	}
 }
 
 func (t *ticketHistoryUsecase) GetTicketID(ctx context.Context, id int64) (*model.TicketHistory, error) {
@@ -21,6 +26,12 @@ func (t *ticketHistoryUsecase) GetTicketID(ctx context.Context, id int64) (*mode
 		"id": id,
 	})
 
+	_, err := findReadableTicket(ctx, t.ticketRepo, id)
+	if err != nil {
+		log.Error("Failed to fetch ticket: ", err)
+		return nil, err
+	}
+
 	ticketHistory, err := t.ticketHistoryRepo.GetTicketID(ctx, id)
 	if err != nil {
 		log.Error("Failed to fetch ticket history by ID: ", err)
@@ -40,6 +51,12 @@ func (t *ticketHistoryUsecase) GetStatus(ctx context.Context, status string) (*[
 		"status": status,
 	})
 
+	err := checkReadAllTickets(ctx)
+	if err != nil {
+		log.Error("You are not authorized to search ticket history: ", err)
+		return nil, err
+	}
+
 	ticketHistory, err := t.ticketHistoryRepo.GetStatus(ctx, status)
 	if err != nil {
 		log.Error("Failed to fetch ticket history by status: ", err)
@@ -59,6 +76,12 @@ func (t *ticketHistoryUsecase) GetPriority(ctx context.Context, priority string)
 		"priority": priority,
 	})
 
+	err := checkReadAllTickets(ctx)
+	if err != nil {
+		log.Error("You are not authorized to search ticket history: ", err)
+		return nil, err
+	}
+
 	ticketHistory, err := t.ticketHistoryRepo.GetPriority(ctx, priority)
 	if err != nil {
 		log.Error("Failed to fetch ticket history by priority: ", err)
@@ -78,6 +101,18 @@ func (t *ticketHistoryUsecase) GetUserID(ctx context.Context, userID int64) (*[]
 		"user_id": userID,
 	})
 
+	// customers may see the changes they made themselves
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		log.Error("Failed to get claims: ", err)
+		return nil, err
+	}
+
+	if !canReadAllTickets(claims) && claims.UserID != userID {
+		log.Error("You are not authorized to view this user's ticket history")
+		return nil, model.ErrForbidden
+	}
+
 	ticketHistory, err := t.ticketHistoryRepo.GetUserID(ctx, userID)
 	if err != nil {
 		log.Error("Failed to fetch ticket history by user ID: ", err)
@@ -91,3 +126,53 @@ func (t *ticketHistoryUsecase) GetUserID(ctx context.Context, userID int64) (*[]
 
 	return ticketHistory, nil
 }
+
+func (t *ticketHistoryUsecase) GetPausedIntervals(ctx context.Context, ticketID int64) ([]model.PausedInterval, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"ticket_id": ticketID,
+	})
+
+	_, err := findReadableTicket(ctx, t.ticketRepo, ticketID)
+	if err != nil {
+		log.Error("Failed to fetch ticket: ", err)
+		return nil, err
+	}
+
+	histories, err := t.ticketHistoryRepo.FindAllByTicketID(ctx, ticketID)
+	if err != nil {
+		log.Error("Failed to fetch ticket histories: ", err)
+		return nil, err
+	}
+
+	return helper.PausedIntervals(histories), nil
+}
+
+func (t *ticketHistoryUsecase) Record(ctx context.Context, event model.DomainEvent) error {
+	ticket := event.EventTicket()
+
+	var from string
+	if updated, ok := event.(*model.TicketUpdated); ok {
+		from = updated.Previous.Status
+	}
+
+	return t.ticketHistoryRepo.Create(ctx, model.TicketHistory{
+		TicketID:   ticket.ID,
+		UserID:     event.EventMeta().ActorID,
+		FromStatus: from,
+		Status:     ticket.Status,
+		Priority:   ticket.Priority,
+		ChangedAt:  event.EventMeta().OccurredAt,
+	})
+}
+
+func (t *ticketHistoryUsecase) Index(ctx context.Context, event model.DomainEvent) error {
+	ticketID := event.EventTicket().ID
+
+	histories, err := t.ticketHistoryRepo.FindAllByTicketID(ctx, ticketID)
+	if err != nil {
+		logrus.WithField("ticket_id", ticketID).Error("Failed to fetch ticket histories: ", err)
+		return err
+	}
+
+	return t.ticketHistoryRepo.Index(ctx, histories)
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+type TicketTransitionUsecase struct {
+	ticketTransitionRepo model.ITicketTransitionRepository
+}
+
+func NewTicketTransitionUsecase(ticketTransitionRepo model.ITicketTransitionRepository) model.ITicketTransitionUsecase {
+	return &TicketTransitionUsecase{ticketTransitionRepo: ticketTransitionRepo}
+}
+
+func (t *TicketTransitionUsecase) FindAll(ctx context.Context) ([]*model.TicketTransition, error) {
+	transitions, err := t.ticketTransitionRepo.FindAll(ctx)
+	if err != nil {
+		logrus.Error("Failed to fetch ticket transitions: ", err)
+		return nil, err
+	}
+
+	return transitions, nil
+}
+
+func (t *TicketTransitionUsecase) Create(ctx context.Context, in model.CreateTicketTransitionInput) (*model.TicketTransition, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"input": in,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage ticket transitions")
+		return nil, model.ErrForbidden
+	}
+
+	err := helper.Validator.Struct(in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	transition, err := t.ticketTransitionRepo.Create(ctx, model.TicketTransition{
+		FromStatus: in.FromStatus,
+		ToStatus:   in.ToStatus,
+		Roles:      pq.StringArray(in.Roles),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	})
+	if err != nil {
+		log.Error("Failed to create ticket transition: ", err)
+		return nil, err
+	}
+
+	return transition, nil
+}
+
+func (t *TicketTransitionUsecase) Update(ctx context.Context, id int64, in model.UpdateTicketTransitionInput) (*model.TicketTransition, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"id":    id,
+		"input": in,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage ticket transitions")
+		return nil, model.ErrForbidden
+	}
+
+	err := helper.Validator.Struct(in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	transition, err := t.ticketTransitionRepo.FindById(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch ticket transition: ", err)
+		return nil, err
+	}
+
+	transition.Roles = pq.StringArray(in.Roles)
+	transition.UpdatedAt = time.Now()
+
+	transition, err = t.ticketTransitionRepo.Update(ctx, *transition)
+	if err != nil {
+		log.Error("Failed to update ticket transition: ", err)
+		return nil, err
+	}
+
+	return transition, nil
+}
+
+func (t *TicketTransitionUsecase) Delete(ctx context.Context, id int64) error {
+	log := logrus.WithFields(logrus.Fields{
+		"id": id,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage ticket transitions")
+		return model.ErrForbidden
+	}
+
+	_, err := t.ticketTransitionRepo.FindById(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch ticket transition: ", err)
+		return err
+	}
+
+	err = t.ticketTransitionRepo.Delete(ctx, id)
+	if err != nil {
+		log.Error("Failed to delete ticket transition: ", err)
+		return err
+	}
+
+	log.Info("Successfully deleted ticket transition with ID: ", id)
+	return nil
+}
+
+// Check returns ErrInvalidTransition unless a transition from one status to
+// the other is configured and open to the caller's role. Keeping the same
+// status is always allowed.
+func (t *TicketTransitionUsecase) Check(ctx context.Context, from string, to string) error {
+	if from == to {
+		return nil
+	}
+
+	log := logrus.WithFields(logrus.Fields{
+		"from": from,
+		"to":   to,
+	})
+
+	transition, err := t.ticketTransitionRepo.FindByStatuses(ctx, from, to)
+	if err != nil {
+		log.Error("Failed to fetch ticket transition: ", err)
+		return err
+	}
+
+	if transition == nil {
+		return fmt.Errorf("%w: %s -> %s", model.ErrInvalidTransition, from, to)
+	}
+
+	if len(transition.Roles) == 0 {
+		return nil
+	}
+
+	role, err := helper.GetUserRole(ctx)
+	if err != nil {
+		log.Error("Failed to get user role: ", err)
+		return err
+	}
+
+	for _, allowed := range transition.Roles {
+		if allowed == role {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s -> %s is restricted to %v", model.ErrInvalidTransition, from, to, []string(transition.Roles))
+}
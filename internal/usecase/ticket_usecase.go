@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"helpdesk-ticketing-system/internal/config"
 	"helpdesk-ticketing-system/internal/helper"
 	"helpdesk-ticketing-system/internal/model"
 	"time"
@@ -13,13 +14,16 @@ import (
 )
 
 type TicketUsecase struct {
-	ticketRepo          model.ITicketRepository
-	userRepo            model.IUserRepository
-	commentRepo         model.ICommentRepository
-	attachmentRepo      model.IAttachmentRepository
-	ticketHistoryRepo   model.ITicketHistoryRepository
-	notificationUsecase model.INotificationUsecase
-	rmq                 *amqp.Channel
+	ticketRepo              model.ITicketRepository
+	userRepo                model.IUserRepository
+	commentRepo             model.ICommentRepository
+	attachmentRepo          model.IAttachmentRepository
+	ticketHistoryRepo       model.ITicketHistoryRepository
+	slaPolicyUsecase        model.ISLAPolicyUsecase
+	ticketTransitionUsecase model.ITicketTransitionUsecase
+	eventBus                model.IEventBus
+	unitOfWork              model.IUnitOfWork
+	rmq                     *amqp.Channel
 }
 
 func NewTicketUsecase(
@@ -28,93 +32,73 @@ func NewTicketUsecase(
 	commentRepo model.ICommentRepository,
 	attachmentRepo model.IAttachmentRepository,
 	ticketHistoryRepo model.ITicketHistoryRepository,
-	notificationUsecase model.INotificationUsecase,
+	slaPolicyUsecase model.ISLAPolicyUsecase,
+	ticketTransitionUsecase model.ITicketTransitionUsecase,
+	eventBus model.IEventBus,
+	unitOfWork model.IUnitOfWork,
 	rmq *amqp.Channel,
 ) model.ITicketUsecase {
 	return &TicketUsecase{
-		ticketRepo:          ticketRepo,
-		userRepo:            userRepo,
-		commentRepo:         commentRepo,
-		attachmentRepo:      attachmentRepo,
-		ticketHistoryRepo:   ticketHistoryRepo,
-		notificationUsecase: notificationUsecase,
-		rmq:                 rmq,
+		ticketRepo:              ticketRepo,
+		userRepo:                userRepo,
+		commentRepo:             commentRepo,
+		attachmentRepo:          attachmentRepo,
+		ticketHistoryRepo:       ticketHistoryRepo,
+		slaPolicyUsecase:        slaPolicyUsecase,
+		ticketTransitionUsecase: ticketTransitionUsecase,
+		eventBus:                eventBus,
+		unitOfWork:              unitOfWork,
+		rmq:                     rmq,
 	}
 }
 
-func (t *TicketUsecase) FindAll(ctx context.Context, filter model.FindAllParam) ([]*model.TicketResponse, error) {
+func (t *TicketUsecase) FindAll(ctx context.Context, filter model.FindAllParam) (*model.TicketPage, error) {
 	log := logrus.WithFields(logrus.Fields{
 		"filter": filter,
 	})
 
-	tickets, err := t.ticketRepo.FindAll(ctx, filter)
+	err := helper.Validator.Struct(filter)
 	if err != nil {
-		log.Error("Failed to fetch tickets: ", err)
-		return nil, err
+		log.Error("Validation error: ", err)
+		return nil, fmt.Errorf("%w: %s", model.ErrInvalidFilter, err)
 	}
 
-	var responses []*model.TicketResponse
-
-	for _, ticket := range tickets {
-		user, _ := t.userRepo.FindById(ctx, ticket.UserID)
-		comments, _ := t.commentRepo.FindAllByTicketID(ctx, ticket.ID)
-		attachments, _ := t.attachmentRepo.FindAllByTicketID(ctx, ticket.ID)
-
-		var userRes *model.UserResponse
-		if user != nil {
-			userRes = &model.UserResponse{
-				Name:  user.Name,
-				Email: user.Email,
-			}
+	if filter.Cursor != "" {
+		sortBy, _, _, err := helper.DecodeSortCursor(filter.Cursor)
+		if err != nil {
+			log.Error("Validation error: ", err)
+			return nil, fmt.Errorf("%w: %s", model.ErrInvalidFilter, err)
 		}
 
-		var commentResList []*model.CommentResponse
-		for _, comment := range comments {
-			commentResList = append(commentResList, &model.CommentResponse{
-				UserID:  comment.UserID,
-				Content: comment.Content,
-			})
-		}
-
-		var attachmentResList []*model.AttachmentResponseForTicket
-		for _, attachment := range attachments {
-			attachmentResList = append(attachmentResList, &model.AttachmentResponseForTicket{
-				FilePath:   attachment.FilePath,
-				UploadedAt: attachment.UploadedAt,
-			})
+		if sortBy != model.TicketSortColumn(filter.SortBy) {
+			log.Error("Cursor was issued for another sort: ", sortBy)
+			return nil, fmt.Errorf("%w: cursor does not match sort_by", model.ErrInvalidFilter)
 		}
+	}
 
-		penalty := false
-		var overdueBy string
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		log.Error("Failed to get claims: ", err)
+		return nil, err
+	}
 
-		if (ticket.Status == "open" || ticket.Status == "in_progress") && time.Now().After(*ticket.DueBy) {
-			penalty = true
-			overdueDuration := time.Since(*ticket.DueBy)
-			overdueBy = helper.FormatDuration(overdueDuration)
-		}
+	if claims.Role == model.RoleCustomer {
+		filter.UserID = claims.UserID
+	}
 
-		response := &model.TicketResponse{
-			ID:          ticket.ID,
-			Title:       ticket.Title,
-			Description: ticket.Description,
-			Status:      ticket.Status,
-			Priority:    ticket.Priority,
-			AssignedTo:  ticket.AssignedTo,
-			UserID:      ticket.UserID,
-			User:        userRes,
-			Comment:     commentResList,
-			Attachment:  attachmentResList,
-			DueBy:       ticket.DueBy,
-			CreatedAt:   ticket.CreatedAt,
-			UpdatedAt:   ticket.UpdatedAt,
-			Penalty:     penalty,
-			Overdueby:   overdueBy,
-		}
+	page, err := t.ticketRepo.FindAll(ctx, filter)
+	if err != nil {
+		log.Error("Failed to fetch tickets: ", err)
+		return nil, err
+	}
 
-		responses = append(responses, response)
+	err = t.loadTicketRelations(ctx, page.Tickets, filter.Include)
+	if err != nil {
+		log.Error("Failed to load ticket relations: ", err)
+		return nil, err
 	}
 
-	return responses, nil
+	return page, nil
 }
 
 func (t *TicketUsecase) FindById(ctx context.Context, id int64) (*model.TicketResponse, error) {
@@ -133,62 +117,175 @@ func (t *TicketUsecase) FindById(ctx context.Context, id int64) (*model.TicketRe
 		return nil, errors.New("ticket not found")
 	}
 
-	user, _ := t.userRepo.FindById(ctx, ticket.UserID)
-	comments, _ := t.commentRepo.FindAllByTicketID(ctx, ticket.ID)
-	attachments, _ := t.attachmentRepo.FindAllByTicketID(ctx, ticket.ID)
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		log.Error("Failed to get claims: ", err)
+		return nil, err
+	}
+
+	if !canReadTicket(claims, ticket) {
+		log.Error("You are not authorized to view this ticket")
+		return nil, model.ErrForbidden
+	}
+
+	response := &model.TicketResponse{
+		ID:                 ticket.ID,
+		Title:              ticket.Title,
+		Description:        ticket.Description,
+		Status:             ticket.Status,
+		Priority:           ticket.Priority,
+		AssignedTo:         ticket.AssignedTo,
+		UserID:             ticket.UserID,
+		Category:           ticket.Category,
+		Queue:              ticket.Queue,
+		SLAPolicyID:        ticket.SLAPolicyID,
+		FirstResponseDueBy: ticket.FirstResponseDueBy,
+		FirstRespondedAt:   ticket.FirstRespondedAt,
+		DueBy:              ticket.DueBy,
+		CreatedAt:          ticket.CreatedAt,
+		UpdatedAt:          ticket.UpdatedAt,
+	}
+
+	err = t.loadTicketRelations(ctx, []*model.TicketResponse{response}, nil)
+	if err != nil {
+		log.Error("Failed to load ticket relations: ", err)
+		return nil, err
+	}
 
-	var userRes *model.UserResponse
-	if user != nil {
-		userRes = &model.UserResponse{
-			Name:  user.Name,
-			Email: user.Email,
+	return response, nil
+}
+
+// loadTicketRelations fills in the requester, comments, attachments and
+// overdue state of the tickets with one query per relation, however many
+// tickets there are. include limits the embedded relations; nil means all.
+func (t *TicketUsecase) loadTicketRelations(ctx context.Context, tickets []*model.TicketResponse, include []string) error {
+	if len(tickets) == 0 {
+		return nil
+	}
+
+	ticketIDs := make([]int64, 0, len(tickets))
+	userIDs := make([]int64, 0, len(tickets))
+	for _, ticket := range tickets {
+		ticketIDs = append(ticketIDs, ticket.ID)
+		userIDs = append(userIDs, ticket.UserID)
+	}
+
+	if includes(include, model.IncludeUser) {
+		users, err := t.userRepo.FindByIDs(ctx, userIDs)
+		if err != nil {
+			return err
+		}
+
+		userByID := make(map[int64]*model.UserResponse, len(users))
+		for _, user := range users {
+			userByID[user.ID] = &model.UserResponse{
+				Name:  user.Name,
+				Email: user.Email,
+			}
+		}
+
+		for _, ticket := range tickets {
+			ticket.User = userByID[ticket.UserID]
 		}
 	}
 
-	var commentResList []*model.CommentResponse
-	for _, comment := range comments {
-		commentResList = append(commentResList, &model.CommentResponse{
-			UserID:  comment.UserID,
-			Content: comment.Content,
-		})
+	if includes(include, model.IncludeComments) {
+		comments, err := t.commentRepo.FindAllByTicketIDs(ctx, ticketIDs)
+		if err != nil {
+			return err
+		}
+
+		commentsByTicket := make(map[int64][]*model.CommentResponse)
+		for _, comment := range comments {
+			commentsByTicket[comment.TicketID] = append(commentsByTicket[comment.TicketID], &model.CommentResponse{
+				UserID:  comment.UserID,
+				Content: comment.Content,
+			})
+		}
+
+		for _, ticket := range tickets {
+			ticket.Comment = commentsByTicket[ticket.ID]
+		}
 	}
 
-	var attachmentResList []*model.AttachmentResponseForTicket
-	for _, attachment := range attachments {
-		attachmentResList = append(attachmentResList, &model.AttachmentResponseForTicket{
-			FilePath:   attachment.FilePath,
-			UploadedAt: attachment.UploadedAt,
-		})
+	if includes(include, model.IncludeAttachments) {
+		attachments, err := t.attachmentRepo.FindAllByTicketIDs(ctx, ticketIDs)
+		if err != nil {
+			return err
+		}
+
+		attachmentsByTicket := make(map[int64][]*model.AttachmentResponseForTicket)
+		for _, attachment := range attachments {
+			attachmentsByTicket[attachment.TicketID] = append(attachmentsByTicket[attachment.TicketID], &model.AttachmentResponseForTicket{
+				ID:         attachment.ID,
+				Filename:   attachment.Filename,
+				MimeType:   attachment.MimeType,
+				Size:       attachment.Size,
+				ScanStatus: attachment.ScanStatus,
+				UploadedAt: attachment.UploadedAt,
+			})
+		}
+
+		for _, ticket := range tickets {
+			ticket.Attachment = attachmentsByTicket[ticket.ID]
+		}
+	}
+
+	calendars := make(map[int64]*model.BusinessCalendar)
+	for _, ticket := range tickets {
+		ticket.Penalty, ticket.Overdueby = t.overdue(ctx, ticket, calendars)
 	}
 
-	penalty := false
-	var overdueBy string
+	return nil
+}
 
-	if (ticket.Status == "open" || ticket.Status == "in_progress") && time.Now().After(*ticket.DueBy) {
-		penalty = true
-		overdueDuration := time.Since(*ticket.DueBy)
-		overdueBy = helper.FormatDuration(overdueDuration)
+func includes(include []string, relation string) bool {
+	if include == nil {
+		return true
 	}
 
-	response := &model.TicketResponse{
-		ID:          ticket.ID,
-		Title:       ticket.Title,
-		Description: ticket.Description,
-		Status:      ticket.Status,
-		Priority:    ticket.Priority,
-		AssignedTo:  ticket.AssignedTo,
-		UserID:      ticket.UserID,
-		Attachment:  attachmentResList,
-		User:        userRes,
-		Comment:     commentResList,
-		DueBy:       ticket.DueBy,
-		CreatedAt:   ticket.CreatedAt,
-		UpdatedAt:   ticket.UpdatedAt,
-		Penalty:     penalty,
-		Overdueby:   overdueBy,
+	for _, name := range include {
+		if name == relation {
+			return true
+		}
 	}
 
-	return response, nil
+	return false
+}
+
+// overdue reports whether an open ticket has missed a due date and by how
+// much business time, by the same rule as the overdue filter. calendars
+// caches the calendar per SLA policy ID, with 0 standing for tickets without
+// a policy.
+func (t *TicketUsecase) overdue(ctx context.Context, ticket *model.TicketResponse, calendars map[int64]*model.BusinessCalendar) (bool, string) {
+	now := time.Now()
+	dueBy := model.TicketMissedDueDate(ticket.Status, ticket.DueBy, ticket.FirstResponseDueBy, ticket.FirstRespondedAt, now)
+	if dueBy == nil {
+		return false, ""
+	}
+
+	slaPolicyID := ticket.SLAPolicyID
+	var key int64
+	if slaPolicyID != nil {
+		key = *slaPolicyID
+	}
+
+	calendar, ok := calendars[key]
+	if !ok {
+		var err error
+		calendar, err = t.slaPolicyUsecase.CalendarFor(ctx, slaPolicyID)
+		if err != nil {
+			logrus.Warn("Failed to fetch business calendar: ", err)
+		}
+		calendars[key] = calendar
+	}
+
+	overdueDuration := helper.BusinessDuration(*dueBy, now, calendar)
+	if overdueDuration <= 0 {
+		return false, ""
+	}
+
+	return true, helper.FormatDuration(overdueDuration)
 }
 
 func (t *TicketUsecase) Create(ctx context.Context, in model.CreateTicketInput) (*model.Ticket, error) {
@@ -208,6 +305,15 @@ func (t *TicketUsecase) Create(ctx context.Context, in model.CreateTicketInput)
 		return &model.Ticket{}, err
 	}
 
+	if in.Status == "" {
+		in.Status = model.TicketStatusOpen
+	}
+
+	if in.Status != model.TicketStatusOpen {
+		log.Error("New tickets must start open")
+		return &model.Ticket{}, fmt.Errorf("%w: new tickets must start %s", model.ErrInvalidTransition, model.TicketStatusOpen)
+	}
+
 	ticket := model.Ticket{
 		Title:       in.Title,
 		Description: in.Description,
@@ -215,46 +321,37 @@ func (t *TicketUsecase) Create(ctx context.Context, in model.CreateTicketInput)
 		Priority:    in.Priority,
 		AssignedTo:  in.AssignedTo,
 		UserID:      userID,
-		DueBy:       helper.CalculateDueBy(in.Priority),
+		Category:    in.Category,
+		Queue:       in.Queue,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}
 
-	tickets, err := t.ticketRepo.Create(ctx, ticket)
+	err = t.slaPolicyUsecase.ApplyToTicket(ctx, &ticket)
 	if err != nil {
-		log.Error("Failed to create ticket: ", err)
+		log.Error("Failed to apply SLA policy: ", err)
 		return &model.Ticket{}, err
 	}
 
-	assignedUser, err := t.userRepo.FindById(ctx, in.AssignedTo)
+	_, err = t.userRepo.FindById(ctx, in.AssignedTo)
 	if err != nil {
 		log.Error("Failed to fetch assigned user: ", err)
 		return nil, fmt.Errorf("failed to fetch assigned user")
 	}
 
-	notification := model.Notification{
-		UserID:    assignedUser.ID,
-		Email:     assignedUser.Email,
-		Subject:   tickets.Title,
-		Message:   tickets.Description,
-		Status:    "pending",
-		TicketID:  tickets.ID,
-		CreatedAt: time.Now(),
-	}
-
-	err = t.notificationUsecase.SendNotification(ctx, &notification)
-	if err != nil {
-		log.Warn("Failed to send notification: ", err)
-		return nil, err
-	}
-
-	ticketHistory := model.TicketHistory{
-		TicketID:  tickets.ID,
-		UserID:    userID,
-		Status:    tickets.Status,
-		Priority:  tickets.Priority,
-		ChangedAt: time.Now(),
-	}
+	var tickets *model.Ticket
+	err = t.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		tickets, err = t.ticketRepo.Create(ctx, ticket)
+		if err != nil {
+			log.Error("Failed to create ticket: ", err)
+			return err
+		}
 
-	err = t.ticketHistoryRepo.Create(ctx, ticketHistory)
+		return t.eventBus.Publish(ctx, &model.TicketCreated{
+			DomainEventMeta: model.DomainEventMeta{ActorID: userID},
+			Ticket:          tickets,
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -274,9 +371,9 @@ func (t *TicketUsecase) Update(ctx context.Context, id int64, in model.UpdateTic
 		return &model.Ticket{}, err
 	}
 
-	userID, err := helper.GetUserID(ctx)
+	claims, err := helper.GetClaims(ctx)
 	if err != nil {
-		log.Error("Failed to get user ID: ", err)
+		log.Error("Failed to get claims: ", err)
 		return &model.Ticket{}, err
 	}
 
@@ -291,31 +388,55 @@ func (t *TicketUsecase) Update(ctx context.Context, id int64, in model.UpdateTic
 		return &model.Ticket{}, errors.New("ticket is deleted or does not exist")
 	}
 
+	if !canUpdateTicket(claims, exitingTicket) {
+		log.Error("You are not authorized to update this ticket")
+		return &model.Ticket{}, model.ErrForbidden
+	}
+
+	previous := *exitingTicket
+
+	err = t.ticketTransitionUsecase.Check(ctx, previous.Status, in.Status)
+	if err != nil {
+		log.Error("Invalid status transition: ", err)
+		return &model.Ticket{}, err
+	}
+
+	// only changes that can select a different SLA target move the due dates
+	slaChanged := exitingTicket.Priority != in.Priority ||
+		exitingTicket.Category != in.Category ||
+		exitingTicket.Queue != in.Queue
+	leavingPending := exitingTicket.Status == model.TicketStatusPending &&
+		in.Status != model.TicketStatusPending
+
 	func(ticket *model.Ticket, input model.UpdateTicketInput) {
 		ticket.Title = input.Title
 		ticket.Description = input.Description
 		ticket.Status = input.Status
 		ticket.Priority = input.Priority
 		ticket.AssignedTo = input.AssignedTo
-		ticket.UserID = userID
-		ticket.DueBy = helper.CalculateDueBy(input.Priority)
+		ticket.Category = input.Category
+		ticket.Queue = input.Queue
 		ticket.UpdatedAt = time.Now()
 	}(exitingTicket, in)
 
-	tickets, err := t.ticketRepo.Update(ctx, *exitingTicket)
-	if err != nil {
-		log.Error("Failed to update ticket: ", err)
-		return &model.Ticket{}, err
+	if slaChanged || leavingPending {
+		err = t.applySLA(ctx, exitingTicket, slaChanged, leavingPending)
+		if err != nil {
+			log.Error("Failed to apply SLA policy: ", err)
+			return &model.Ticket{}, err
+		}
 	}
 
-	ticketHistory := model.TicketHistory{
-		TicketID:  tickets.ID,
-		UserID:    tickets.UserID,
-		Status:    tickets.Status,
-		Priority:  tickets.Priority,
-		ChangedAt: time.Now(),
-	}
-	err = t.ticketHistoryRepo.Create(ctx, ticketHistory)
+	var tickets *model.Ticket
+	err = t.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		tickets, err = t.ticketRepo.Update(ctx, *exitingTicket)
+		if err != nil {
+			log.Error("Failed to update ticket: ", err)
+			return err
+		}
+
+		return t.eventBus.Publish(ctx, ticketUpdateEvents(claims.UserID, &previous, tickets)...)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -323,11 +444,108 @@ func (t *TicketUsecase) Update(ctx context.Context, id int64, in model.UpdateTic
 	return tickets, nil
 }
 
+// ticketUpdateEvents returns the events for an edit: TicketUpdated, then a
+// status change and a new assignee when there was one.
+func ticketUpdateEvents(actorID int64, previous *model.Ticket, ticket *model.Ticket) []model.DomainEvent {
+	meta := model.DomainEventMeta{ActorID: actorID}
+
+	events := []model.DomainEvent{&model.TicketUpdated{
+		DomainEventMeta: meta,
+		Ticket:          ticket,
+		Previous:        previous,
+	}}
+
+	if previous.Status != ticket.Status {
+		events = append(events, &model.TicketStatusChanged{
+			DomainEventMeta: meta,
+			Ticket:          ticket,
+			From:            previous.Status,
+			To:              ticket.Status,
+		})
+	}
+
+	if previous.AssignedTo != ticket.AssignedTo {
+		events = append(events, &model.TicketAssigned{
+			DomainEventMeta:  meta,
+			Ticket:           ticket,
+			PreviousAssignee: previous.AssignedTo,
+		})
+	}
+
+	return events
+}
+
+// applySLA moves the ticket due dates forward by the business time it spent
+// pending. When the SLA target changed the due dates are recalculated from
+// creation, so every paused interval is added back; otherwise only the pause
+// that is ending now is.
+func (t *TicketUsecase) applySLA(ctx context.Context, ticket *model.Ticket, slaChanged bool, leavingPending bool) error {
+	if slaChanged {
+		err := t.slaPolicyUsecase.ApplyToTicket(ctx, ticket)
+		if err != nil {
+			return err
+		}
+	}
+
+	histories, err := t.ticketHistoryRepo.FindAllByTicketID(ctx, ticket.ID)
+	if err != nil {
+		return err
+	}
+
+	intervals := helper.PausedIntervals(histories)
+	if len(intervals) == 0 {
+		return nil
+	}
+
+	last := intervals[len(intervals)-1]
+	if last.To == nil && !leavingPending {
+		// still pending, the open pause is added once the ticket leaves it
+		intervals = intervals[:len(intervals)-1]
+	}
+
+	if !slaChanged {
+		if !leavingPending || last.To != nil {
+			return nil
+		}
+		intervals = []model.PausedInterval{last}
+	}
+
+	calendar, err := t.slaPolicyUsecase.CalendarFor(ctx, ticket.SLAPolicyID)
+	if err != nil {
+		return err
+	}
+
+	paused := helper.PausedBusinessDuration(intervals, time.Now(), calendar)
+	if paused <= 0 {
+		return nil
+	}
+
+	if ticket.FirstResponseDueBy != nil {
+		ticket.FirstResponseDueBy = helper.CalculateDueBy(*ticket.FirstResponseDueBy, paused, calendar)
+	}
+	if ticket.DueBy != nil {
+		ticket.DueBy = helper.CalculateDueBy(*ticket.DueBy, paused, calendar)
+	}
+
+	return nil
+}
+
 func (t *TicketUsecase) Delete(ctx context.Context, id int64) error {
 	log := logrus.WithFields(logrus.Fields{
 		"id": id,
 	})
 
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		log.Error("Failed to get claims: ", err)
+		return err
+	}
+
+	if !canDeleteTicket(claims) {
+		log.Error("Only admins can delete tickets")
+		return model.ErrForbidden
+	}
+
 	ticket, err := t.ticketRepo.FindById(ctx, id)
 	if err != nil {
 		log.Error("Failed to fetch ticket: ", err)
@@ -353,3 +571,74 @@ func (t *TicketUsecase) Delete(ctx context.Context, id int64) error {
 	log.Info("Successfully deleted ticket with ID: ", id)
 	return nil
 }
+
+// slaBreachBatchSize caps how many tickets one scan claims per target, so a
+// backlog of breaches is warned about over several scans.
+const slaBreachBatchSize = 100
+
+func (t *TicketUsecase) RecordFirstResponse(ctx context.Context, event model.DomainEvent) error {
+	e, ok := event.(*model.CommentAdded)
+	if !ok || e.Ticket.FirstRespondedAt != nil || e.Comment.UserID == e.Ticket.UserID {
+		return nil
+	}
+
+	respondedAt := e.Comment.CreatedAt
+	if respondedAt.IsZero() {
+		respondedAt = time.Now()
+	}
+
+	err := t.ticketRepo.SetFirstResponse(ctx, e.Ticket.ID, respondedAt)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"ticket_id": e.Ticket.ID,
+		}).Error("Failed to record first response: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (t *TicketUsecase) ScanSLABreaches(ctx context.Context) (int, error) {
+	before := time.Now().Add(config.SLAWarningBefore())
+
+	raised := 0
+	for _, target := range []string{model.SLATargetFirstResponse, model.SLATargetResolution} {
+		log := logrus.WithFields(logrus.Fields{
+			"target": target,
+		})
+
+		err := t.unitOfWork.Do(ctx, func(ctx context.Context) error {
+			tickets, err := t.ticketRepo.ClaimSLABreaches(ctx, target, before, slaBreachBatchSize)
+			if err != nil {
+				log.Error("Failed to claim SLA breaches: ", err)
+				return err
+			}
+
+			events := make([]model.DomainEvent, 0, len(tickets))
+			for _, ticket := range tickets {
+				dueBy := ticket.DueBy
+				if target == model.SLATargetFirstResponse {
+					dueBy = ticket.FirstResponseDueBy
+				}
+
+				events = append(events, &model.TicketSLAAtRisk{
+					Ticket: ticket,
+					Target: target,
+					DueBy:  *dueBy,
+				})
+			}
+
+			if err := t.eventBus.Publish(ctx, events...); err != nil {
+				return err
+			}
+
+			raised += len(events)
+			return nil
+		})
+		if err != nil {
+			return raised, err
+		}
+	}
+
+	return raised, nil
+}
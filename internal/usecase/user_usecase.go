@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"helpdesk-ticketing-system/internal/config"
 	"helpdesk-ticketing-system/internal/helper"
 	"helpdesk-ticketing-system/internal/model"
 
@@ -15,6 +16,9 @@ import (
 
 var v = validator.New()
 
+// streamTicketTTL is how long a stream ticket can wait before it is used.
+const streamTicketTTL = 30 * time.Second
+
 type UserUsecase struct {
 	userRepo model.IUserRepository
 }
@@ -27,55 +31,156 @@ func NewUserUsecase(
 	}
 }
 
-func (u *UserUsecase) Login(ctx context.Context, in model.LoginInput) (token string, err error) {
+func (u *UserUsecase) Login(ctx context.Context, in model.LoginInput) (*model.TokenPair, error) {
 	log := logrus.WithFields(logrus.Fields{
 		"email": in.Email,
 	})
 
 	if err := v.Struct(in); err != nil {
 		log.Error("Validation error: ", err)
-		return "", err
+		return nil, err
 	}
 
 	user := u.userRepo.FindByEmail(ctx, in.Email)
 	if user == nil {
-		return "", errors.New("wrong email or password")
+		return nil, errors.New("wrong email or password")
 	}
 
 	if !helper.CheckPasswordHash(in.Password, user.Password) {
-		return "", errors.New("mismatch password")
+		return nil, errors.New("mismatch password")
 	}
 
-	token, err = helper.GenerateToken(user.ID)
+	tokens, err := u.createSession(ctx, user, "")
 	if err != nil {
-		log.Error(err)
-		return "", err
+		log.Error("Failed to create session:", err)
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (u *UserUsecase) Refresh(ctx context.Context, in model.RefreshTokenInput) (*model.TokenPair, error) {
+	if err := v.Struct(in); err != nil {
+		logrus.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	session, err := u.userRepo.FindSessionByRefreshToken(ctx, helper.HashToken(in.RefreshToken))
+	if err != nil {
+		logrus.Error("Failed to fetch session: ", err)
+		return nil, model.ErrInvalidRefreshToken
+	}
+
+	log := logrus.WithFields(logrus.Fields{
+		"user_id":   session.UserID,
+		"family_id": session.FamilyID,
+	})
+
+	if session.RevokedAt != nil {
+		return nil, u.revokeReusedFamily(ctx, session)
+	}
+
+	if session.RefreshExpiresAt.Before(time.Now()) {
+		log.Error("Refresh token expired")
+		return nil, model.ErrInvalidRefreshToken
+	}
+
+	rotated, err := u.userRepo.RevokeSession(ctx, *session)
+	if err != nil {
+		log.Error("Failed to revoke session: ", err)
+		return nil, err
+	}
+
+	if !rotated {
+		return nil, u.revokeReusedFamily(ctx, session)
+	}
+
+	user, err := u.userRepo.FindById(ctx, session.UserID)
+	if err != nil || (user.DeletedAt != nil && !user.DeletedAt.IsZero()) {
+		log.Error("User is deleted or does not exist")
+		return nil, model.ErrInvalidRefreshToken
+	}
+
+	tokens, err := u.createSession(ctx, user, session.FamilyID)
+	if err != nil {
+		log.Error("Failed to create session:", err)
+		return nil, err
 	}
 
-	err = u.userRepo.DeleteSession(ctx, token)
+	return tokens, nil
+}
+
+// revokeReusedFamily is called when an already rotated refresh token is
+// presented again. The token has most likely leaked, so every session
+// descending from the same login is revoked.
+func (u *UserUsecase) revokeReusedFamily(ctx context.Context, session *model.UserSession) error {
+	log := logrus.WithFields(logrus.Fields{
+		"user_id":   session.UserID,
+		"family_id": session.FamilyID,
+	})
+
+	log.Warn("Refresh token reuse detected, revoking token family")
+
+	err := u.userRepo.RevokeSessionFamily(ctx, session.FamilyID)
 	if err != nil {
-		log.Warn("Failed to delete old session:", err)
+		log.Error("Failed to revoke token family: ", err)
+		return err
+	}
+
+	return model.ErrRefreshTokenReused
+}
+
+// createSession issues an access/refresh token pair for the user and records
+// it in user_sessions, so AuthMiddleware accepts the access token until it
+// expires or is revoked. An empty familyID starts a new token family.
+func (u *UserUsecase) createSession(ctx context.Context, user *model.User, familyID string) (*model.TokenPair, error) {
+	accessToken, err := helper.GenerateToken(user.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := helper.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	if familyID == "" {
+		familyID, err = helper.RandomToken(16)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	session := model.UserSession{
-		UserID:    user.ID,
-		Token:     token,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
-		CreatedAt: time.Now(),
+		UserID:           user.ID,
+		FamilyID:         familyID,
+		Token:            accessToken,
+		RefreshTokenHash: helper.HashToken(refreshToken),
+		ExpiresAt:        time.Now().Add(config.JWTExp()),
+		RefreshExpiresAt: time.Now().Add(config.JWTRefreshExp()),
+		CreatedAt:        time.Now(),
 	}
 	_, err = u.userRepo.CreateSession(ctx, session)
 	if err != nil {
-		log.Error("Failed to create session:", err)
-		return "", err
+		return nil, err
 	}
 
-	return token, nil
+	return &model.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
 }
+
 func (u *UserUsecase) FindAll(ctx context.Context, user model.User) ([]*model.User, error) {
 	log := logrus.WithFields(logrus.Fields{
 		"filter": user,
 	})
 
+	if !isAdmin(ctx) {
+		log.Error("Only admins can list users")
+		return nil, model.ErrForbidden
+	}
+
 	users, err := u.userRepo.FindAll(ctx, user)
 	if err != nil {
 		log.Error("Failed to fetch users: ", err)
@@ -100,6 +205,54 @@ func (u *UserUsecase) Logout(ctx context.Context, token string) error {
 	return nil
 }
 
+func (u *UserUsecase) LogoutAll(ctx context.Context, userID int64) error {
+	log := logrus.WithFields(logrus.Fields{
+		"user_id": userID,
+	})
+
+	err := u.userRepo.DeleteSessionsByUserID(ctx, userID)
+	if err != nil {
+		log.Error("Failed to delete sessions: ", err)
+		return err
+	}
+
+	log.Info("Successfully logged out from all devices")
+	return nil
+}
+
+func (u *UserUsecase) IssueStreamTicket(ctx context.Context, accessToken string) (*model.StreamTicket, error) {
+	ticket, err := helper.RandomToken(32)
+	if err != nil {
+		logrus.Error("Failed to generate stream ticket: ", err)
+		return nil, err
+	}
+
+	err = u.userRepo.SaveStreamTicket(ctx, helper.HashToken(ticket), accessToken, streamTicketTTL)
+	if err != nil {
+		logrus.Error("Failed to save stream ticket: ", err)
+		return nil, err
+	}
+
+	return &model.StreamTicket{
+		Ticket:    ticket,
+		ExpiresAt: time.Now().Add(streamTicketTTL),
+	}, nil
+}
+
+func (u *UserUsecase) RedeemStreamTicket(ctx context.Context, ticket string) (string, error) {
+	accessToken, err := u.userRepo.TakeStreamTicket(ctx, helper.HashToken(ticket))
+	if err != nil {
+		logrus.Error("Failed to fetch stream ticket: ", err)
+		return "", err
+	}
+
+	if accessToken == "" {
+		return "", errors.New("stream ticket expired or already used")
+	}
+
+	return accessToken, nil
+}
+
 func (u *UserUsecase) ValidateSession(ctx context.Context, token string) (*model.UserSession, error) {
 	session, err := u.userRepo.FindSessionByToken(ctx, token)
 	if err != nil {
@@ -119,6 +272,17 @@ func (u *UserUsecase) FindById(ctx context.Context, id int64) (*model.User, erro
 		"id": id,
 	})
 
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		log.Error("Failed to get claims: ", err)
+		return nil, err
+	}
+
+	if !canManageUser(claims, id) {
+		log.Error("You are not authorized to view this user")
+		return nil, model.ErrForbidden
+	}
+
 	user, err := u.userRepo.FindById(ctx, int64(id))
 	if err != nil {
 		log.Error("Failed to fetch user by ID: ", err)
@@ -133,11 +297,26 @@ func (u *UserUsecase) FindById(ctx context.Context, id int64) (*model.User, erro
 	return user, nil
 }
 
-func (u *UserUsecase) Create(ctx context.Context, in model.CreateUserInput) (token string, err error) {
+func (u *UserUsecase) Create(ctx context.Context, in model.CreateUserInput) (tokens *model.TokenPair, err error) {
 	logger := logrus.WithFields(logrus.Fields{
 		"in": in,
 	})
 
+	if in.Role == "" {
+		in.Role = model.RoleCustomer
+	}
+
+	err = v.Struct(in)
+	if err != nil {
+		logger.Error("Validation error: ", err)
+		return
+	}
+
+	if in.Role != model.RoleCustomer && !isAdmin(ctx) {
+		logger.Error("Only admins can create non-customer accounts")
+		return nil, model.ErrForbidden
+	}
+
 	passwordHashed, err := helper.HashRequestPassword(in.Password)
 	if err != nil {
 		logger.Error(err)
@@ -156,13 +335,13 @@ func (u *UserUsecase) Create(ctx context.Context, in model.CreateUserInput) (tok
 		return
 	}
 
-	accessToken, err := helper.GenerateToken(newUser.ID)
+	tokens, err = u.createSession(ctx, newUser, "")
 	if err != nil {
 		logger.Error(err)
 		return
 	}
 
-	return accessToken, nil
+	return tokens, nil
 }
 
 func (u *UserUsecase) Update(ctx context.Context, id int64, in model.UpdateUserInput) error {
@@ -179,6 +358,17 @@ func (u *UserUsecase) Update(ctx context.Context, id int64, in model.UpdateUserI
 		return err
 	}
 
+	claims, err := helper.GetClaims(ctx)
+	if err != nil {
+		log.Error("Failed to get claims: ", err)
+		return err
+	}
+
+	if !canManageUser(claims, id) {
+		log.Error("You are not authorized to update this user")
+		return model.ErrForbidden
+	}
+
 	existingUser, err := u.userRepo.FindById(ctx, id)
 	if err != nil {
 		log.Error("Failed to fetch user: ", err)
@@ -189,6 +379,11 @@ func (u *UserUsecase) Update(ctx context.Context, id int64, in model.UpdateUserI
 		return errors.New("user is deleted or does not exist")
 	}
 
+	if in.Role != existingUser.Role && claims.Role != model.RoleAdmin {
+		log.Error("Only admins can change roles")
+		return model.ErrForbidden
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
 	if err != nil {
 		log.Error("Failed to hash password: ", err)
@@ -210,6 +405,16 @@ func (u *UserUsecase) Update(ctx context.Context, id int64, in model.UpdateUserI
 		return err
 	}
 
+	// the role is carried in the access token, so a changed role only takes
+	// effect once the user logs in again
+	if in.Role != existingUser.Role {
+		err = u.userRepo.DeleteSessionsByUserID(ctx, id)
+		if err != nil {
+			log.Error("Failed to revoke sessions: ", err)
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -218,6 +423,11 @@ func (u *UserUsecase) Delete(ctx context.Context, id int64) error {
 		"id": id,
 	})
 
+	if !isAdmin(ctx) {
+		log.Error("Only admins can delete users")
+		return model.ErrForbidden
+	}
+
 	user, err := u.userRepo.FindById(ctx, id)
 	if err != nil {
 		log.Error("Failed to find user for deletion: ", err)
@@ -226,7 +436,7 @@ func (u *UserUsecase) Delete(ctx context.Context, id int64) error {
 
 	if user == nil {
 		log.Error("User not found")
-		return err
+		return errors.New("user not found")
 	}
 
 	now := time.Now()
@@ -238,6 +448,12 @@ func (u *UserUsecase) Delete(ctx context.Context, id int64) error {
 		return err
 	}
 
+	err = u.userRepo.DeleteSessionsByUserID(ctx, id)
+	if err != nil {
+		log.Error("Failed to revoke sessions: ", err)
+		return err
+	}
+
 	log.Info("Successfully deleted user with ID: ", id)
 	return nil
 }
@@ -0,0 +1,449 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const webhookDeliveryTimeout = 10 * time.Second
+
+// webhookEnvelope is the JSON body posted to webhook subscriptions.
+type webhookEnvelope struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	TicketID   int64           `json:"ticket_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+type WebhookUsecase struct {
+	webhookSubscriptionRepo model.IWebhookSubscriptionRepository
+	webhookDeliveryRepo     model.IWebhookDeliveryRepository
+	outboxUsecase           model.IOutboxUsecase
+	unitOfWork              model.IUnitOfWork
+	client                  *http.Client
+}
+
+func NewWebhookUsecase(
+	webhookSubscriptionRepo model.IWebhookSubscriptionRepository,
+	webhookDeliveryRepo model.IWebhookDeliveryRepository,
+	outboxUsecase model.IOutboxUsecase,
+	unitOfWork model.IUnitOfWork,
+) model.IWebhookUsecase {
+	return &WebhookUsecase{
+		webhookSubscriptionRepo: webhookSubscriptionRepo,
+		webhookDeliveryRepo:     webhookDeliveryRepo,
+		outboxUsecase:           outboxUsecase,
+		unitOfWork:              unitOfWork,
+		client:                  helper.NewPublicHTTPClient(webhookDeliveryTimeout),
+	}
+}
+
+func (w *WebhookUsecase) FindSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	if !isAdmin(ctx) {
+		logrus.Error("Only admins can manage webhooks")
+		return nil, model.ErrForbidden
+	}
+
+	subscriptions, err := w.webhookSubscriptionRepo.FindAll(ctx)
+	if err != nil {
+		logrus.Error("Failed to fetch webhook subscriptions: ", err)
+		return nil, err
+	}
+
+	for _, subscription := range subscriptions {
+		subscription.Secret = ""
+	}
+
+	return subscriptions, nil
+}
+
+func (w *WebhookUsecase) CreateSubscription(ctx context.Context, in model.CreateWebhookSubscriptionInput) (*model.WebhookSubscription, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"url":    in.URL,
+		"events": in.Events,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage webhooks")
+		return nil, model.ErrForbidden
+	}
+
+	err := helper.Validator.Struct(in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	// deliveries check the address again when they connect
+	err = helper.CheckPublicURL(ctx, in.URL)
+	if err != nil {
+		log.Error("Webhook URL is not public: ", err)
+		return nil, err
+	}
+
+	userID, err := helper.GetUserID(ctx)
+	if err != nil {
+		log.Error("Failed to get user ID: ", err)
+		return nil, err
+	}
+
+	secret := in.Secret
+	if secret == "" {
+		secret, err = helper.RandomToken(32)
+		if err != nil {
+			log.Error("Failed to generate webhook secret: ", err)
+			return nil, err
+		}
+	}
+
+	subscription, err := w.webhookSubscriptionRepo.Create(ctx, model.WebhookSubscription{
+		URL:       in.URL,
+		Events:    in.Events,
+		Secret:    secret,
+		Active:    true,
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Error("Failed to create webhook subscription: ", err)
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// UpdateSubscription returns the secret only when it was rotated.
+func (w *WebhookUsecase) UpdateSubscription(ctx context.Context, id int64, in model.UpdateWebhookSubscriptionInput) (*model.WebhookSubscription, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"id":     id,
+		"url":    in.URL,
+		"events": in.Events,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage webhooks")
+		return nil, model.ErrForbidden
+	}
+
+	err := helper.Validator.Struct(in)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, err
+	}
+
+	// deliveries check the address again when they connect
+	err = helper.CheckPublicURL(ctx, in.URL)
+	if err != nil {
+		log.Error("Webhook URL is not public: ", err)
+		return nil, err
+	}
+
+	subscription, err := w.webhookSubscriptionRepo.FindById(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch webhook subscription: ", err)
+		return nil, err
+	}
+
+	subscription.URL = in.URL
+	subscription.Events = in.Events
+	subscription.Active = in.Active
+	subscription.UpdatedAt = time.Now()
+
+	if in.RotateSecret {
+		subscription.Secret, err = helper.RandomToken(32)
+		if err != nil {
+			log.Error("Failed to generate webhook secret: ", err)
+			return nil, err
+		}
+	}
+
+	subscription, err = w.webhookSubscriptionRepo.Update(ctx, *subscription)
+	if err != nil {
+		log.Error("Failed to update webhook subscription: ", err)
+		return nil, err
+	}
+
+	if !in.RotateSecret {
+		subscription.Secret = ""
+	}
+
+	return subscription, nil
+}
+
+func (w *WebhookUsecase) DeleteSubscription(ctx context.Context, id int64) error {
+	log := logrus.WithFields(logrus.Fields{
+		"id": id,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage webhooks")
+		return model.ErrForbidden
+	}
+
+	_, err := w.webhookSubscriptionRepo.FindById(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch webhook subscription: ", err)
+		return err
+	}
+
+	err = w.webhookSubscriptionRepo.Delete(ctx, id)
+	if err != nil {
+		log.Error("Failed to delete webhook subscription: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (w *WebhookUsecase) FindDeliveries(ctx context.Context, subscriptionID int64, param model.WebhookDeliveryParam) (*model.WebhookDeliveryPage, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"subscription_id": subscriptionID,
+		"param":           param,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage webhooks")
+		return nil, model.ErrForbidden
+	}
+
+	err := helper.Validator.Struct(param)
+	if err != nil {
+		log.Error("Validation error: ", err)
+		return nil, fmt.Errorf("%w: %s", model.ErrInvalidFilter, err)
+	}
+
+	if param.Cursor != "" {
+		_, _, err = helper.DecodeCursor(param.Cursor)
+		if err != nil {
+			log.Error("Validation error: ", err)
+			return nil, fmt.Errorf("%w: %s", model.ErrInvalidFilter, err)
+		}
+	}
+
+	page, err := w.webhookDeliveryRepo.FindAllBySubscriptionID(ctx, subscriptionID, param)
+	if err != nil {
+		log.Error("Failed to fetch webhook deliveries: ", err)
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// ReplayDelivery queues the delivery again with its original payload. The
+// attempt count starts over.
+func (w *WebhookUsecase) ReplayDelivery(ctx context.Context, id int64) (*model.WebhookDelivery, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"id": id,
+	})
+
+	if !isAdmin(ctx) {
+		log.Error("Only admins can manage webhooks")
+		return nil, model.ErrForbidden
+	}
+
+	delivery, err := w.webhookDeliveryRepo.FindById(ctx, id)
+	if err != nil {
+		log.Error("Failed to fetch webhook delivery: ", err)
+		return nil, err
+	}
+
+	_, err = w.webhookSubscriptionRepo.FindById(ctx, delivery.SubscriptionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Webhook subscription no longer exists")
+		return nil, model.ErrWebhookGone
+	}
+	if err != nil {
+		log.Error("Failed to fetch webhook subscription: ", err)
+		return nil, err
+	}
+
+	err = w.unitOfWork.Do(ctx, func(ctx context.Context) error {
+		err := w.webhookDeliveryRepo.UpdateResult(ctx, id, model.WebhookDeliveryResult{
+			Status: model.WebhookDeliveryPending,
+		})
+		if err != nil {
+			return err
+		}
+
+		return w.outboxUsecase.Enqueue(ctx, config.NotificationExchange, config.WebhookDeliveryQueue, model.WebhookDeliveryMessage{
+			DeliveryID: id,
+		})
+	})
+	if err != nil {
+		log.Error("Failed to replay webhook delivery: ", err)
+		return nil, err
+	}
+
+	delivery.Status = model.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.ResponseStatus = 0
+	delivery.LastError = ""
+
+	return delivery, nil
+}
+
+func (w *WebhookUsecase) Dispatch(ctx context.Context, domainEvent model.DomainEvent) error {
+	log := logrus.WithFields(logrus.Fields{
+		"event_id": domainEvent.EventMeta().ID,
+		"type":     domainEvent.EventName(),
+	})
+
+	subscriptions, err := w.webhookSubscriptionRepo.FindActiveByEvent(ctx, domainEvent.EventName())
+	if err != nil {
+		log.Error("Failed to fetch webhook subscriptions: ", err)
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	event, err := newTicketEvent(domainEvent)
+	if err != nil {
+		log.Error("Failed to encode webhook payload: ", err)
+		return err
+	}
+
+	payload, err := json.Marshal(webhookEnvelope{
+		ID:         event.ID,
+		Type:       event.Type,
+		TicketID:   event.TicketID,
+		OccurredAt: event.OccurredAt,
+		Data:       event.Data,
+	})
+	if err != nil {
+		log.Error("Failed to encode webhook payload: ", err)
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		delivery := &model.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         model.WebhookDeliveryPending,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}
+
+		err := w.webhookDeliveryRepo.Create(ctx, delivery)
+		if err != nil {
+			log.Error("Failed to create webhook delivery: ", err)
+			return err
+		}
+
+		err = w.outboxUsecase.Enqueue(ctx, config.NotificationExchange, config.WebhookDeliveryQueue, model.WebhookDeliveryMessage{
+			DeliveryID: delivery.ID,
+		})
+		if err != nil {
+			log.Error("Failed to queue webhook delivery: ", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *WebhookUsecase) Deliver(ctx context.Context, id int64, attempt int) (*model.WebhookDelivery, error) {
+	log := logrus.WithFields(logrus.Fields{
+		"id":      id,
+		"attempt": attempt,
+	})
+
+	// deleting a subscription deletes its deliveries, so a delivery that is
+	// gone was queued for a subscription that is gone
+	delivery, err := w.webhookDeliveryRepo.FindById(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Warn("Webhook delivery no longer exists")
+		return nil, model.ErrWebhookGone
+	}
+	if err != nil {
+		log.Error("Failed to fetch webhook delivery: ", err)
+		return nil, err
+	}
+
+	subscription, err := w.webhookSubscriptionRepo.FindById(ctx, delivery.SubscriptionID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Error("Failed to fetch webhook subscription: ", err)
+		return nil, err
+	}
+
+	result := model.WebhookDeliveryResult{Attempts: attempt}
+	if subscription == nil {
+		result.Status = model.WebhookDeliveryFailed
+		result.LastError = "subscription was deleted"
+	} else if !subscription.Active {
+		// a disabled subscription gets nothing, and retrying will not help
+		result.Status = model.WebhookDeliveryFailed
+		result.LastError = "subscription is inactive"
+	} else {
+		result.ResponseStatus, err = w.post(ctx, subscription, delivery)
+		switch {
+		case err == nil:
+			result.Status = model.WebhookDeliverySent
+		case attempt > len(config.RetryDelays):
+			result.Status = model.WebhookDeliveryFailed
+			result.LastError = err.Error()
+		default:
+			result.Status = model.WebhookDeliveryRetrying
+			result.LastError = err.Error()
+		}
+	}
+
+	recordErr := w.webhookDeliveryRepo.UpdateResult(ctx, id, result)
+	if recordErr != nil {
+		log.Error("Failed to record webhook delivery: ", recordErr)
+	}
+
+	delivery.Status = result.Status
+	delivery.Attempts = result.Attempts
+	delivery.ResponseStatus = result.ResponseStatus
+	delivery.LastError = result.LastError
+
+	return delivery, err
+}
+
+// post sends the signed payload and returns the response status. Any non-2xx
+// response is an error.
+func (w *WebhookUsecase) post(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "helpdesk-webhooks/1.0")
+	req.Header.Set("X-Webhook-Id", delivery.EventID)
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", helper.SignWebhook(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("webhook responded %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/model"
+	"log"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// StartAttachmentScanWorker consumes queued attachment scans. Scans that fail,
+// such as while clamd is down, go through the same delay queues as
+// notifications; the attachment stays quarantined if the retries run out.
+func StartAttachmentScanWorker(conn *amqp.Connection, attachmentUsecase model.IAttachmentUsecase) {
+	queue := config.AttachmentScanQueue
+
+	consume(conn, queue, 0, func(ch *amqp.Channel, d amqp.Delivery) {
+		handleAttachmentScan(ch, queue, attachmentUsecase, d)
+	})
+}
+
+func handleAttachmentScan(ch *amqp.Channel, queue string, attachmentUsecase model.IAttachmentUsecase, d amqp.Delivery) {
+	var msg model.AttachmentScanMessage
+	err := json.Unmarshal(d.Body, &msg)
+	if err != nil {
+		log.Println("Failed to decode attachment scan message:", err)
+		settleNotification(ch, d, config.DeadLetterQueue(queue), 0)
+		return
+	}
+
+	attempts := deliveryAttempts(d) + 1
+
+	attachment, err := attachmentUsecase.Scan(context.Background(), msg.AttachmentID)
+	if err == nil {
+		log.Printf("Attachment %d scanned as %s", msg.AttachmentID, attachment.ScanStatus)
+		d.Ack(false)
+		return
+	}
+
+	log.Printf("Failed to scan attachment %d (attempt %d): %v", msg.AttachmentID, attempts, err)
+
+	if attempts > len(config.RetryDelays) {
+		settleNotification(ch, d, config.DeadLetterQueue(queue), attempts)
+		return
+	}
+
+	settleNotification(ch, d, config.DelayQueue(queue, attempts), attempts)
+}
@@ -0,0 +1,40 @@
+package worker
+
+import (
+	"context"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"net/http"
+	"strings"
+)
+
+// chatPayload is the incoming webhook body understood by both Slack and
+// Mattermost.
+type chatPayload struct {
+	Text string `json:"text"`
+}
+
+// ChatDriver posts the plain text rendering of the notification to a Slack or
+// Mattermost incoming webhook.
+type ChatDriver struct {
+	emailTemplates *helper.EmailTemplates
+	client         *http.Client
+}
+
+func NewChatDriver(emailTemplates *helper.EmailTemplates) model.INotificationDriver {
+	return &ChatDriver{
+		emailTemplates: emailTemplates,
+		client:         helper.NewPublicHTTPClient(webhookTimeout),
+	}
+}
+
+func (c *ChatDriver) Deliver(ctx context.Context, notification *model.Notification) error {
+	rendered, err := c.emailTemplates.Render(notification)
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, c.client, notification.Target, chatPayload{
+		Text: "*" + rendered.Subject + "*\n" + strings.TrimSpace(rendered.Text),
+	})
+}
@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"helpdesk-ticketing-system/internal/config"
+	"log"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const consumerReconnectDelay = 5 * time.Second
+
+// consume keeps a consumer of queue running in the background. Every
+// consumer has a channel of its own, so a channel error such as a failed ack
+// only stops that consumer, and it consumes again on a new channel after
+// consumerReconnectDelay. Once conn itself is closed the consumer dials a
+// connection of its own. A prefetch of 0 leaves the channel unlimited.
+func consume(conn *amqp.Connection, queue string, prefetch int, handle func(ch *amqp.Channel, d amqp.Delivery)) {
+	go func() {
+		for {
+			if conn.IsClosed() {
+				redialed, err := config.DialRabbitMQ()
+				if err != nil {
+					log.Printf("Consumer of %s failed to reconnect to RabbitMQ: %v", queue, err)
+					time.Sleep(consumerReconnectDelay)
+					continue
+				}
+				conn = redialed
+			}
+
+			err := consumeChannel(conn, queue, prefetch, handle)
+			log.Printf("Consumer of %s stopped: %v", queue, err)
+			time.Sleep(consumerReconnectDelay)
+		}
+	}()
+}
+
+// consumeChannel consumes queue on a new channel until the channel closes.
+func consumeChannel(conn *amqp.Connection, queue string, prefetch int, handle func(ch *amqp.Channel, d amqp.Delivery)) error {
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if prefetch > 0 {
+		err = ch.Qos(prefetch, 0, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	closed := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+	msgs, err := ch.Consume(
+		queue,
+		"",
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	for d := range msgs {
+		handle(ch, d)
+	}
+
+	return channelClosedError(<-closed)
+}
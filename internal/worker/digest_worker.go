@@ -0,0 +1,34 @@
+package worker
+
+import (
+	"context"
+	"helpdesk-ticketing-system/internal/model"
+	"log"
+	"time"
+)
+
+// StartDigestWorker periodically sends the digests of held notifications
+// that are due, either at the end of quiet hours or at the digest time of
+// their recipient. An interval of zero leaves digests to the digest command.
+func StartDigestWorker(notificationUsecase model.INotificationUsecase, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			sent, err := notificationUsecase.SendDigests(context.Background())
+			if err != nil {
+				log.Println("Failed to send notification digests:", err)
+				continue
+			}
+
+			if sent > 0 {
+				log.Printf("Sent %d notification digests", sent)
+			}
+		}
+	}()
+}
@@ -0,0 +1,98 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StartMaildirWatcher polls the new/ folder of a maildir and hands every
+// message to the inbound email usecase. Processed messages move to cur/ and
+// messages that are larger than maxSize, cannot be parsed or are rejected to
+// failed/; anything else stays in new/ and is tried again on the next poll.
+func StartMaildirWatcher(dir string, interval time.Duration, maxSize int64, inboundEmailUsecase model.IInboundEmailUsecase) {
+	for _, sub := range []string{"new", "cur", "tmp", "failed"} {
+		err := os.MkdirAll(filepath.Join(dir, sub), 0o755)
+		if err != nil {
+			log.Println("Failed to create maildir:", err)
+			return
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for ; true; <-ticker.C {
+			entries, err := os.ReadDir(filepath.Join(dir, "new"))
+			if err != nil {
+				log.Println("Failed to read maildir:", err)
+				continue
+			}
+
+			for _, entry := range entries {
+				if entry.IsDir() {
+					continue
+				}
+				handleMaildirMessage(dir, entry.Name(), maxSize, inboundEmailUsecase)
+			}
+		}
+	}()
+}
+
+func handleMaildirMessage(dir string, name string, maxSize int64, inboundEmailUsecase model.IInboundEmailUsecase) {
+	path := filepath.Join(dir, "new", name)
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Printf("Failed to open inbound message %s: %v", name, err)
+		return
+	}
+
+	// the same limit as the SMTP listener, so an oversized drop is never
+	// read whole
+	var body bytes.Buffer
+	_, err = io.Copy(&body, io.LimitReader(file, maxSize+1))
+	file.Close()
+	if err != nil {
+		log.Printf("Failed to read inbound message %s: %v", name, err)
+		return
+	}
+
+	if int64(body.Len()) > maxSize {
+		log.Printf("Inbound message %s exceeds %d bytes", name, maxSize)
+		os.Rename(path, filepath.Join(dir, "failed", name))
+		return
+	}
+
+	email, err := helper.ParseInboundEmail(&body)
+	if err != nil {
+		log.Printf("Failed to parse inbound message %s: %v", name, err)
+		os.Rename(path, filepath.Join(dir, "failed", name))
+		return
+	}
+
+	_, err = inboundEmailUsecase.Process(context.Background(), email)
+	if errors.Is(err, model.ErrInboundRejected) {
+		log.Printf("Rejected inbound message %s: %v", name, err)
+		os.Rename(path, filepath.Join(dir, "failed", name))
+		return
+	}
+	if err != nil && !errors.Is(err, model.ErrAutoReply) {
+		log.Printf("Failed to process inbound message %s: %v", name, err)
+		return
+	}
+
+	// ":2,S" marks the message as seen in maildir terms
+	err = os.Rename(path, filepath.Join(dir, "cur", name+":2,S"))
+	if err != nil {
+		log.Printf("Failed to move inbound message %s: %v", name, err)
+	}
+}
@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"io"
+	"log"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+const smtpCommandTimeout = 5 * time.Minute
+
+// StartInboundSMTP accepts mail on addr and hands every message to the
+// inbound email usecase. It speaks just enough SMTP for a local MTA to relay
+// to it and has no authentication or TLS, so it must only listen on a
+// private interface.
+func StartInboundSMTP(addr string, domain string, maxSize int64, inboundEmailUsecase model.IInboundEmailUsecase) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			if err != nil {
+				log.Println("Failed to accept SMTP connection:", err)
+				continue
+			}
+
+			go serveSMTP(conn, domain, maxSize, inboundEmailUsecase)
+		}
+	}()
+
+	return nil
+}
+
+func serveSMTP(conn net.Conn, domain string, maxSize int64, inboundEmailUsecase model.IInboundEmailUsecase) {
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	reply := func(code int, message string) {
+		text.PrintfLine("%d %s", code, message)
+	}
+
+	var from string
+	var recipients int
+
+	reply(220, domain+" ESMTP helpdesk")
+	for {
+		conn.SetDeadline(time.Now().Add(smtpCommandTimeout))
+
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			reply(250, domain)
+		case "EHLO":
+			text.PrintfLine("250-%s", domain)
+			text.PrintfLine("250-SIZE %d", maxSize)
+			text.PrintfLine("250 8BITMIME")
+		case "MAIL":
+			if !strings.HasPrefix(strings.ToUpper(arg), "FROM:") {
+				reply(501, "Syntax: MAIL FROM:<address>")
+				continue
+			}
+			from, recipients = arg[len("FROM:"):], 0
+			reply(250, "OK")
+		case "RCPT":
+			if from == "" {
+				reply(503, "Need MAIL before RCPT")
+				continue
+			}
+			recipients++
+			reply(250, "OK")
+		case "DATA":
+			if recipients == 0 {
+				reply(503, "Need RCPT before DATA")
+				continue
+			}
+			reply(354, "End data with <CR><LF>.<CR><LF>")
+
+			code, message := receiveSMTPMessage(text, maxSize, inboundEmailUsecase)
+			reply(code, message)
+			from, recipients = "", 0
+		case "RSET":
+			from, recipients = "", 0
+			reply(250, "OK")
+		case "NOOP":
+			reply(250, "OK")
+		case "VRFY":
+			reply(252, "Cannot verify user")
+		case "QUIT":
+			reply(221, "Bye")
+			return
+		default:
+			reply(502, "Command not implemented")
+		}
+	}
+}
+
+// receiveSMTPMessage reads the DATA section and returns the reply for it.
+// Rejected messages get a permanent error and any other processing error a
+// temporary one, so the sending MTA only retries what can still succeed.
+func receiveSMTPMessage(text *textproto.Conn, maxSize int64, inboundEmailUsecase model.IInboundEmailUsecase) (int, string) {
+	dot := text.DotReader()
+
+	var body bytes.Buffer
+	_, err := io.Copy(&body, io.LimitReader(dot, maxSize+1))
+	if err != nil {
+		return 451, "Failed to read message"
+	}
+
+	if int64(body.Len()) > maxSize {
+		// drain the rest so the connection stays usable
+		io.Copy(io.Discard, dot)
+		return 552, fmt.Sprintf("Message exceeds %d bytes", maxSize)
+	}
+
+	email, err := helper.ParseInboundEmail(&body)
+	if err != nil {
+		log.Println("Failed to parse inbound message:", err)
+		return 554, "Malformed message"
+	}
+
+	_, err = inboundEmailUsecase.Process(context.Background(), email)
+	if errors.Is(err, model.ErrInboundRejected) {
+		log.Println("Rejected inbound message:", err)
+		return 554, "Message rejected"
+	}
+	if err != nil && !errors.Is(err, model.ErrAutoReply) {
+		log.Println("Failed to process inbound message:", err)
+		return 451, "Failed to process message, try again later"
+	}
+
+	return 250, "OK"
+}
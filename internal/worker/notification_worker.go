@@ -0,0 +1,127 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/model"
+	"log"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// StartNotificationWorker consumes the queue of one notification channel with
+// manual acks and hands each message to the driver. A failed delivery is
+// parked on the delay queue for its attempt and comes back once the delay
+// expires; after the last retry the message moves to the dead-letter queue.
+// Every outcome is recorded on the notification row.
+func StartNotificationWorker(conn *amqp.Connection, queue string, notificationUsecase model.INotificationUsecase, driver model.INotificationDriver) {
+	consume(conn, queue, 10, func(ch *amqp.Channel, d amqp.Delivery) {
+		handleNotification(ch, queue, notificationUsecase, driver, d)
+	})
+}
+
+func handleNotification(ch *amqp.Channel, queue string, notificationUsecase model.INotificationUsecase, driver model.INotificationDriver, d amqp.Delivery) {
+	ctx := context.Background()
+
+	var notif model.Notification
+	err := json.Unmarshal(d.Body, &notif)
+	if err != nil {
+		// a malformed message never succeeds, so it skips the retries
+		log.Println("Failed to decode notification message:", err)
+		settleNotification(ch, d, config.DeadLetterQueue(queue), 0)
+		return
+	}
+
+	attempts := deliveryAttempts(d) + 1
+
+	err = driver.Deliver(ctx, &notif)
+	if err == nil {
+		log.Printf("Notification %d delivered via %s", notif.ID, notif.Channel)
+		recordDelivery(ctx, notificationUsecase, notif.ID, model.NotificationDelivery{
+			Status:   model.NotificationStatusSent,
+			Attempts: attempts,
+		})
+		d.Ack(false)
+		return
+	}
+
+	log.Printf("Failed to deliver notification %d via %s (attempt %d): %v", notif.ID, notif.Channel, attempts, err)
+
+	if attempts > len(config.RetryDelays) {
+		recordDelivery(ctx, notificationUsecase, notif.ID, model.NotificationDelivery{
+			Status:    model.NotificationStatusFailed,
+			Attempts:  attempts,
+			LastError: err.Error(),
+		})
+		settleNotification(ch, d, config.DeadLetterQueue(queue), attempts)
+		return
+	}
+
+	recordDelivery(ctx, notificationUsecase, notif.ID, model.NotificationDelivery{
+		Status:    model.NotificationStatusRetrying,
+		Attempts:  attempts,
+		LastError: err.Error(),
+	})
+	settleNotification(ch, d, config.DelayQueue(queue, attempts), attempts)
+}
+
+// recordDeliveryDelays is the wait before each retry of recording an outcome.
+var recordDeliveryDelays = []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+
+// recordDelivery stores the outcome on the notification row, retrying while
+// the database is unavailable. The message is settled either way: sending a
+// delivered notification again to fix its status would be worse than a
+// stale status.
+func recordDelivery(ctx context.Context, notificationUsecase model.INotificationUsecase, id int64, delivery model.NotificationDelivery) {
+	err := notificationUsecase.RecordDelivery(ctx, id, delivery)
+	for _, delay := range recordDeliveryDelays {
+		if err == nil {
+			return
+		}
+
+		time.Sleep(delay)
+		err = notificationUsecase.RecordDelivery(ctx, id, delivery)
+	}
+
+	if err != nil {
+		log.Printf("Notification %d is left without its %s status: %v", id, delivery.Status, err)
+	}
+}
+
+// settleNotification republishes the message to queue and only then acks
+// the original, so a failed republish leaves it where it was.
+func settleNotification(ch *amqp.Channel, d amqp.Delivery, queue string, attempts int) {
+	err := ch.Publish(
+		"",
+		queue,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  d.ContentType,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    d.MessageId,
+			Headers:      amqp.Table{config.AttemptsHeader: int32(attempts)},
+			Body:         d.Body,
+		},
+	)
+	if err != nil {
+		log.Printf("Failed to move notification message to %s: %v", queue, err)
+		d.Nack(false, true)
+		return
+	}
+
+	d.Ack(false)
+}
+
+func deliveryAttempts(d amqp.Delivery) int {
+	switch attempts := d.Headers[config.AttemptsHeader].(type) {
+	case int32:
+		return int(attempts)
+	case int64:
+		return int(attempts)
+	}
+
+	return 0
+}
@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/model"
+	"log"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const outboxReconnectDelay = 5 * time.Second
+
+// RunOutboxRelay publishes pending outbox messages until ctx is done. The
+// relay has a RabbitMQ connection of its own in confirm mode and reconnects
+// when the broker closes it. With once set it publishes a single batch.
+func RunOutboxRelay(ctx context.Context, newRelay func(ch *amqp.Channel) model.IOutboxUsecase, once bool) {
+	for {
+		err := relayOutbox(ctx, newRelay, once)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		log.Println("Outbox relay lost its RabbitMQ connection:", err)
+		if once {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(outboxReconnectDelay):
+		}
+	}
+}
+
+// relayOutbox relays over one connection. It returns nil when ctx is done
+// and an error when the connection cannot be opened or is closed.
+func relayOutbox(ctx context.Context, newRelay func(ch *amqp.Channel) model.IOutboxUsecase, once bool) error {
+	conn, err := config.DialRabbitMQ()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ch, err := config.OpenRabbitMQChannel(conn)
+	if err != nil {
+		return err
+	}
+
+	// a message only counts as dispatched once the broker has confirmed it
+	err = ch.Confirm(false)
+	if err != nil {
+		return fmt.Errorf("enable publisher confirms: %w", err)
+	}
+
+	closed := ch.NotifyClose(make(chan *amqp.Error, 1))
+	relay := newRelay(ch)
+
+	for {
+		select {
+		case amqpErr := <-closed:
+			return channelClosedError(amqpErr)
+		default:
+		}
+
+		dispatched, err := relay.Relay(ctx)
+		if err != nil {
+			log.Println("Outbox relay failed:", err)
+		} else if dispatched > 0 {
+			log.Printf("Dispatched %d outbox messages", dispatched)
+		}
+
+		if once {
+			return nil
+		}
+
+		// keep draining while full batches come back
+		if dispatched >= config.OutboxBatchSize() {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case amqpErr := <-closed:
+			return channelClosedError(amqpErr)
+		case <-time.After(config.OutboxPollInterval()):
+		}
+	}
+}
+
+func channelClosedError(amqpErr *amqp.Error) error {
+	if amqpErr == nil {
+		return errors.New("channel closed")
+	}
+
+	return amqpErr
+}
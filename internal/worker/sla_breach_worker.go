@@ -0,0 +1,34 @@
+package worker
+
+import (
+	"context"
+	"helpdesk-ticketing-system/internal/model"
+	"log"
+	"time"
+)
+
+// StartSLABreachWorker periodically warns assignees about tickets whose
+// first response or resolution is about to fall due. An interval of zero
+// turns the scan off.
+func StartSLABreachWorker(ticketUsecase model.ITicketUsecase, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			raised, err := ticketUsecase.ScanSLABreaches(context.Background())
+			if err != nil {
+				log.Println("Failed to scan for SLA breaches:", err)
+				continue
+			}
+
+			if raised > 0 {
+				log.Printf("Raised %d SLA breach warnings", raised)
+			}
+		}
+	}()
+}
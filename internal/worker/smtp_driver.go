@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"net/smtp"
+	"os"
+)
+
+type SMTPDriver struct {
+	emailTemplates *helper.EmailTemplates
+}
+
+func NewSMTPDriver(emailTemplates *helper.EmailTemplates) model.INotificationDriver {
+	return &SMTPDriver{emailTemplates: emailTemplates}
+}
+
+func (s *SMTPDriver) Deliver(ctx context.Context, notification *model.Notification) error {
+	email, err := s.emailTemplates.Render(notification)
+	if err != nil {
+		return err
+	}
+
+	if notification.TicketID != 0 {
+		email.MessageID = helper.NotificationMessageID(notification)
+	}
+
+	return SendEmail(notification.Email, email)
+}
+
+// SendEmail sends the rendered email as multipart/alternative, with plain
+// text and HTML versions of the same content.
+func SendEmail(to string, email *model.RenderedEmail) error {
+	from := os.Getenv("EMAIL_FROM")
+	password := os.Getenv("EMAIL_PASSWORD")
+	smtpHost := os.Getenv("SMTP_HOST")
+	smtpPort := os.Getenv("SMTP_PORT")
+
+	auth := smtp.PlainAuth("", from, password, smtpHost)
+
+	msg, err := helper.BuildMultipartEmail(from, to, email)
+	if err != nil {
+		return err
+	}
+
+	err = smtp.SendMail(smtpHost+":"+smtpPort, auth, from, []string{to}, msg)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
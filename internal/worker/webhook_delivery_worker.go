@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"helpdesk-ticketing-system/internal/config"
+	"helpdesk-ticketing-system/internal/model"
+	"log"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// StartWebhookDeliveryWorker consumes queued webhook deliveries. Failed
+// deliveries go through the same delay queues as notifications, and the
+// usecase marks them failed once the retries run out.
+func StartWebhookDeliveryWorker(conn *amqp.Connection, webhookUsecase model.IWebhookUsecase) {
+	queue := config.WebhookDeliveryQueue
+
+	consume(conn, queue, 0, func(ch *amqp.Channel, d amqp.Delivery) {
+		handleWebhookDelivery(ch, queue, webhookUsecase, d)
+	})
+}
+
+func handleWebhookDelivery(ch *amqp.Channel, queue string, webhookUsecase model.IWebhookUsecase, d amqp.Delivery) {
+	var msg model.WebhookDeliveryMessage
+	err := json.Unmarshal(d.Body, &msg)
+	if err != nil {
+		log.Println("Failed to decode webhook delivery message:", err)
+		settleNotification(ch, d, config.DeadLetterQueue(queue), 0)
+		return
+	}
+
+	attempts := deliveryAttempts(d) + 1
+
+	delivery, err := webhookUsecase.Deliver(context.Background(), msg.DeliveryID, attempts)
+	if errors.Is(err, model.ErrWebhookGone) {
+		// retrying cannot bring the subscription back
+		log.Printf("Dropped webhook delivery %d: %v", msg.DeliveryID, err)
+		d.Ack(false)
+		return
+	}
+	if err == nil {
+		log.Printf("Webhook delivery %d finished as %s", msg.DeliveryID, delivery.Status)
+		d.Ack(false)
+		return
+	}
+
+	log.Printf("Failed to deliver webhook %d (attempt %d): %v", msg.DeliveryID, attempts, err)
+
+	if attempts > len(config.RetryDelays) {
+		settleNotification(ch, d, config.DeadLetterQueue(queue), attempts)
+		return
+	}
+
+	settleNotification(ch, d, config.DelayQueue(queue, attempts), attempts)
+}
@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"helpdesk-ticketing-system/internal/helper"
+	"helpdesk-ticketing-system/internal/model"
+	"io"
+	"net/http"
+	"time"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// webhookPayload is the JSON body posted to generic webhook targets.
+type webhookPayload struct {
+	ID        int64                  `json:"id"`
+	Event     string                 `json:"event"`
+	TicketID  int64                  `json:"ticket_id"`
+	UserID    int64                  `json:"user_id"`
+	Subject   string                 `json:"subject"`
+	Message   string                 `json:"message"`
+	Data      model.NotificationData `json:"data"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type WebhookDriver struct {
+	client *http.Client
+}
+
+func NewWebhookDriver() model.INotificationDriver {
+	return &WebhookDriver{client: helper.NewPublicHTTPClient(webhookTimeout)}
+}
+
+func (w *WebhookDriver) Deliver(ctx context.Context, notification *model.Notification) error {
+	return postJSON(ctx, w.client, notification.Target, webhookPayload{
+		ID:        notification.ID,
+		Event:     notification.Event,
+		TicketID:  notification.TicketID,
+		UserID:    notification.UserID,
+		Subject:   notification.Subject,
+		Message:   notification.Message,
+		Data:      notification.Data,
+		CreatedAt: notification.CreatedAt,
+	})
+}
+
+// postJSON posts payload to url and treats any non-2xx response as a failed
+// delivery.
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook responded %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+
+	return nil
+}